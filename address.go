@@ -0,0 +1,146 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/gocql/gocql"
+)
+
+const (
+	// nodeSchemeSRV is the prefix of nodes discovered using a DNS SRV record, ex: srv://_cql._tcp.cassandra.local
+	nodeSchemeSRV = "srv://"
+	// nodeSchemeDNS is the prefix of nodes discovered by resolving all the addresses of a hostname,
+	// ex: dns://cassandra-headless:9042
+	nodeSchemeDNS = "dns://"
+	// defaultNodePort is the port of the nodes configured without a port.
+	defaultNodePort = 9042
+)
+
+// resolveNodes returns the addresses of the configured nodes, nodes using the srv:// and dns:// schemes are resolved
+// into the addresses they point to.
+func (d *DestinationConfig) resolveNodes(ctx context.Context) ([]string, error) {
+	nodes := make([]string, 0, len(d.Nodes))
+	for _, n := range d.Nodes {
+		switch {
+		case strings.HasPrefix(n, nodeSchemeSRV):
+			_, srvs, err := net.DefaultResolver.LookupSRV(ctx, "", "", strings.TrimPrefix(n, nodeSchemeSRV))
+			if err != nil {
+				return nil, fmt.Errorf("unable to resolve SRV record for node %q: %w", n, err)
+			}
+			for _, srv := range srvs {
+				nodes = append(nodes, net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), strconv.Itoa(int(srv.Port))))
+			}
+		case strings.HasPrefix(n, nodeSchemeDNS):
+			host, port := strings.TrimPrefix(n, nodeSchemeDNS), ""
+			if strings.Contains(host, ":") {
+				var err error
+				host, port, err = net.SplitHostPort(host)
+				if err != nil {
+					return nil, fmt.Errorf("invalid node format %q: %w", n, err)
+				}
+			}
+			addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+			if err != nil {
+				return nil, fmt.Errorf("unable to resolve addresses for node %q: %w", n, err)
+			}
+			for _, addr := range addrs {
+				if port != "" {
+					addr = net.JoinHostPort(addr, port)
+				}
+				nodes = append(nodes, addr)
+			}
+		default:
+			nodes = append(nodes, n)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no nodes were resolved from %v", d.Nodes)
+	}
+	return nodes, nil
+}
+
+// newAddressTranslator returns the translator used to map the addresses advertised by the cluster nodes to addresses
+// reachable by the connector, or nil if no translation is configured. The static map is applied first, then the port
+// offset is added to the resulting port. External hostnames are resolved once, and should resolve to a single address.
+// If the port offset makes a port invalid, the port is not translated.
+func (d *DestinationConfig) newAddressTranslator(ctx context.Context) (gocql.AddressTranslator, error) {
+	if len(d.AddressTranslationStatic) == 0 && d.AddressTranslationPortOffset == 0 {
+		return nil, nil
+	}
+
+	type address struct {
+		ip   net.IP
+		port int
+	}
+	// addresses are mapped either by "ip:port" or by "ip" alone
+	static := make(map[string]address, len(d.AddressTranslationStatic))
+	for _, entry := range d.AddressTranslationStatic {
+		from, to, _ := strings.Cut(entry, "=")
+		toHost, toPort, err := splitOptionalPort(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address translation %q: %w", entry, err)
+		}
+		ips, err := net.DefaultResolver.LookupIP(ctx, "ip", toHost)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve address %q: %w", to, err)
+		}
+		if len(ips) != 1 {
+			return nil, fmt.Errorf("address %q resolves to %d addresses, it should resolve to a single address", to, len(ips))
+		}
+		static[from] = address{ip: ips[0], port: toPort}
+	}
+
+	return gocql.AddressTranslatorFunc(func(addr net.IP, port int) (net.IP, int) {
+		to, ok := static[net.JoinHostPort(addr.String(), strconv.Itoa(port))]
+		if !ok {
+			to, ok = static[addr.String()]
+		}
+		if ok {
+			addr = to.ip
+			if to.port != 0 {
+				port = to.port
+			}
+		}
+		if !validPort(port + d.AddressTranslationPortOffset) {
+			sdk.Logger(ctx).Warn().Int("port", port).Int("offset", d.AddressTranslationPortOffset).
+				Msg("the port offset makes the port invalid, the port is not translated")
+			return addr, port
+		}
+		return addr, port + d.AddressTranslationPortOffset
+	}), nil
+}
+
+// splitOptionalPort splits an address into its host and port, the port is 0 if the address doesn't have one.
+func splitOptionalPort(address string) (string, int, error) {
+	if !strings.Contains(address, ":") {
+		return address, 0, nil
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, err
+	}
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q: %w", port, err)
+	}
+	return host, portNum, nil
+}
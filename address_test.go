@@ -0,0 +1,124 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestConfig_AddressTranslator(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	cfg := DestinationConfig{
+		AddressTranslationStatic: []string{
+			"10.0.0.1=127.0.0.1",
+			"10.0.0.2:9042=127.0.0.2:19042",
+		},
+	}
+	is.NoErr(cfg.validateConfig())
+	translator, err := cfg.newAddressTranslator(ctx)
+	is.NoErr(err)
+
+	ip, port := translator.Translate(net.ParseIP("10.0.0.1"), 9042)
+	is.Equal(ip.String(), "127.0.0.1")
+	is.Equal(port, 9042)
+	ip, port = translator.Translate(net.ParseIP("10.0.0.2"), 9042)
+	is.Equal(ip.String(), "127.0.0.2")
+	is.Equal(port, 19042)
+	// not in the static map
+	ip, port = translator.Translate(net.ParseIP("10.0.0.3"), 9042)
+	is.Equal(ip.String(), "10.0.0.3")
+	is.Equal(port, 9042)
+
+	cfg.AddressTranslationPortOffset = 10000
+	translator, err = cfg.newAddressTranslator(ctx)
+	is.NoErr(err)
+	ip, port = translator.Translate(net.ParseIP("10.0.0.1"), 9042)
+	is.Equal(ip.String(), "127.0.0.1")
+	is.Equal(port, 19042)
+	// the port isn't translated if the offset makes it invalid
+	ip, port = translator.Translate(net.ParseIP("10.0.0.3"), 60000)
+	is.Equal(ip.String(), "10.0.0.3")
+	is.Equal(port, 60000)
+
+	cfg = DestinationConfig{}
+	translator, err = cfg.newAddressTranslator(ctx)
+	is.NoErr(err)
+	is.Equal(translator, nil)
+}
+
+func TestConfig_AddressTranslationValidation(t *testing.T) {
+	testCases := []struct {
+		name       string
+		static     []string
+		nodes      []string
+		portOffset int
+	}{{
+		name:   "missing external address",
+		static: []string{"10.0.0.1"},
+	}, {
+		name:   "internal address is not an IP",
+		static: []string{"cassandra-1=127.0.0.1"},
+	}, {
+		name:   "invalid external port",
+		static: []string{"10.0.0.1=127.0.0.1:0"},
+	}, {
+		name:       "port offset makes an external port invalid",
+		static:     []string{"10.0.0.1=127.0.0.1:60000"},
+		portOffset: 10000,
+	}, {
+		name:       "port offset makes a node port invalid",
+		nodes:      []string{"127.0.0.1"},
+		portOffset: -9042,
+	},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			cfg := DestinationConfig{
+				AddressTranslationStatic:     tt.static,
+				Nodes:                        tt.nodes,
+				AddressTranslationPortOffset: tt.portOffset,
+			}
+			is.True(cfg.validateConfig() != nil)
+		})
+	}
+}
+
+func TestConfig_ResolveNodes(t *testing.T) {
+	is := is.New(t)
+	cfg := DestinationConfig{
+		Nodes: []string{"127.0.0.1:9042", "dns://localhost:9043", "srv://_cql._tcp.cassandra.local"},
+	}
+	is.NoErr(cfg.validateConfig())
+
+	cfg.Nodes = cfg.Nodes[:2]
+	nodes, err := cfg.resolveNodes(context.Background())
+	is.NoErr(err)
+	is.Equal(nodes[0], "127.0.0.1:9042")
+	is.True(len(nodes) > 1)
+	for _, n := range nodes[1:] {
+		_, port, err := net.SplitHostPort(n)
+		is.NoErr(err)
+		is.Equal(port, "9043")
+	}
+
+	cfg.Nodes = []string{"srv://cassandra..local"}
+	is.True(cfg.validateConfig() != nil)
+}
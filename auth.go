@@ -0,0 +1,190 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gocql/gocql"
+)
+
+const (
+	// dseAuthenticator is the authenticator used by DataStax Enterprise, it expects the client to choose the SASL
+	// mechanism before sending the credentials.
+	dseAuthenticator = "com.datastax.bdp.cassandra.auth.DseAuthenticator"
+	// tokenUsername is the username sent with the token when using the token authentication mechanism.
+	tokenUsername = "token"
+)
+
+// credentials used to authenticate to the cluster.
+type credentials struct {
+	username string
+	password string
+	token    string
+}
+
+// loadCredentials returns the credentials from the connector configurations, overridden by the configured environment
+// variables, overridden by the credentials file. It's called for each new connection, so changes to the credentials
+// file are picked up when the connector reconnects.
+func (d *DestinationConfig) loadCredentials() (credentials, error) {
+	creds := credentials{
+		username: d.AuthUsername,
+		password: d.AuthPassword,
+		token:    d.AuthToken,
+	}
+
+	for _, e := range []struct {
+		env   string
+		field *string
+	}{
+		{env: d.AuthUsernameEnv, field: &creds.username},
+		{env: d.AuthPasswordEnv, field: &creds.password},
+		{env: d.AuthTokenEnv, field: &creds.token},
+	} {
+		if e.env == "" {
+			continue
+		}
+		val, ok := os.LookupEnv(e.env)
+		if !ok {
+			return credentials{}, fmt.Errorf("environment variable %q is not set", e.env)
+		}
+		*e.field = val
+	}
+
+	if d.AuthCredentialsFile != "" {
+		content, err := os.ReadFile(d.AuthCredentialsFile)
+		if err != nil {
+			return credentials{}, fmt.Errorf("error while reading the credentials file: %w", err)
+		}
+		err = parseCredentialsFile(content, &creds)
+		if err != nil {
+			return credentials{}, fmt.Errorf("invalid credentials file %q: %w", d.AuthCredentialsFile, err)
+		}
+	}
+
+	return creds, nil
+}
+
+// parseCredentialsFile parses the content of a credentials file into creds. Each line of the file is a key=value
+// pair where the key is one of username, password or token, empty lines and lines starting with # are ignored.
+func parseCredentialsFile(content []byte, creds *credentials) error {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("line should be in the format key=value")
+		}
+		switch strings.TrimSpace(key) {
+		case "username":
+			creds.username = strings.TrimSpace(val)
+		case "password":
+			creds.password = strings.TrimSpace(val)
+		case "token":
+			creds.token = strings.TrimSpace(val)
+		default:
+			return fmt.Errorf("unknown key %q", key)
+		}
+	}
+	return scanner.Err()
+}
+
+// newAuthProvider returns a function that creates the authenticator for each new connection, or nil if
+// authentication is disabled.
+func (d *DestinationConfig) newAuthProvider() func(*gocql.HostInfo) (gocql.Authenticator, error) {
+	if d.AuthMechanism == AuthMechanismNone {
+		return nil
+	}
+	return func(*gocql.HostInfo) (gocql.Authenticator, error) {
+		creds, err := d.loadCredentials()
+		if err != nil {
+			return nil, err
+		}
+		switch d.AuthMechanism {
+		case AuthMechanismPlain:
+			return plainTextAuthenticator{
+				username:              creds.username,
+				password:              creds.password,
+				allowedAuthenticators: d.AuthAllowedAuthenticators,
+			}, nil
+		case AuthMechanismToken:
+			return gocql.PasswordAuthenticator{
+				Username:              tokenUsername,
+				Password:              creds.token,
+				AllowedAuthenticators: d.AuthAllowedAuthenticators,
+			}, nil
+		default:
+			return gocql.PasswordAuthenticator{
+				Username:              creds.username,
+				Password:              creds.password,
+				AllowedAuthenticators: d.AuthAllowedAuthenticators,
+			}, nil
+		}
+	}
+}
+
+// plainTextAuthenticator authenticates using the SASL PLAIN mechanism, compatible with the DSE PlainTextAuthProvider.
+// Unlike gocql.PasswordAuthenticator, it accepts any authenticator if no allowed authenticators are configured, and
+// it negotiates the mechanism with the DSE authenticator before sending the credentials.
+type plainTextAuthenticator struct {
+	username              string
+	password              string
+	allowedAuthenticators []string
+}
+
+func (a plainTextAuthenticator) Challenge(req []byte) ([]byte, gocql.Authenticator, error) {
+	if len(a.allowedAuthenticators) > 0 && !slices.Contains(a.allowedAuthenticators, string(req)) {
+		return nil, nil, fmt.Errorf("unexpected authenticator %q", req)
+	}
+	if string(req) == dseAuthenticator {
+		return []byte("PLAIN"), dsePlainTextChallenge{a}, nil
+	}
+	return a.initialResponse(), nil, nil
+}
+
+func (a plainTextAuthenticator) Success([]byte) error {
+	return nil
+}
+
+// initialResponse returns the SASL PLAIN response, an empty authorization ID followed by the username and password,
+// separated by null bytes.
+func (a plainTextAuthenticator) initialResponse() []byte {
+	resp := make([]byte, 0, 2+len(a.username)+len(a.password))
+	resp = append(resp, 0)
+	resp = append(resp, a.username...)
+	resp = append(resp, 0)
+	resp = append(resp, a.password...)
+	return resp
+}
+
+// dsePlainTextChallenge answers the challenge sent by the DSE authenticator after the PLAIN mechanism was chosen.
+type dsePlainTextChallenge struct {
+	plainTextAuthenticator
+}
+
+func (c dsePlainTextChallenge) Challenge(req []byte) ([]byte, gocql.Authenticator, error) {
+	if string(req) != "PLAIN-START" {
+		return nil, nil, fmt.Errorf("unexpected challenge %q from the DSE authenticator", req)
+	}
+	return c.initialResponse(), nil, nil
+}
@@ -0,0 +1,100 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+)
+
+func TestConfig_LoadCredentials(t *testing.T) {
+	is := is.New(t)
+	t.Setenv("TEST_CASSANDRA_PASSWORD", "env-pass")
+	file := filepath.Join(t.TempDir(), "credentials")
+
+	cfg := DestinationConfig{
+		AuthMechanism:   AuthMechanismBasic,
+		AuthUsername:    "config-user",
+		AuthPassword:    "config-pass",
+		AuthPasswordEnv: "TEST_CASSANDRA_PASSWORD",
+	}
+	creds, err := cfg.loadCredentials()
+	is.NoErr(err)
+	is.Equal(creds, credentials{username: "config-user", password: "env-pass"})
+
+	// the file is read each time, so changes are picked up
+	cfg.AuthCredentialsFile = file
+	is.NoErr(os.WriteFile(file, []byte("# rotated\nusername=file-user\n"), 0o600))
+	creds, err = cfg.loadCredentials()
+	is.NoErr(err)
+	is.Equal(creds, credentials{username: "file-user", password: "env-pass"})
+
+	is.NoErr(os.WriteFile(file, []byte("username=file-user\npassword=file-pass\n"), 0o600))
+	creds, err = cfg.loadCredentials()
+	is.NoErr(err)
+	is.Equal(creds, credentials{username: "file-user", password: "file-pass"})
+
+	is.NoErr(os.WriteFile(file, []byte("secret"), 0o600))
+	_, err = cfg.loadCredentials()
+	is.True(err != nil)
+
+	cfg.AuthCredentialsFile = ""
+	cfg.AuthUsernameEnv = "TEST_CASSANDRA_MISSING"
+	_, err = cfg.loadCredentials()
+	is.True(err != nil)
+}
+
+func TestConfig_AuthProvider(t *testing.T) {
+	is := is.New(t)
+	cfg := DestinationConfig{AuthMechanism: AuthMechanismNone}
+	is.True(cfg.newAuthProvider() == nil)
+
+	cfg = DestinationConfig{AuthMechanism: AuthMechanismToken, AuthToken: "AstraCS:secret"}
+	is.NoErr(cfg.validateConfig())
+	auth, err := cfg.newAuthProvider()(nil)
+	is.NoErr(err)
+	is.Equal(auth, gocql.PasswordAuthenticator{Username: tokenUsername, Password: "AstraCS:secret"})
+
+	cfg = DestinationConfig{AuthMechanism: AuthMechanismToken}
+	is.True(cfg.validateConfig() != nil)
+}
+
+func TestPlainTextAuthenticator(t *testing.T) {
+	is := is.New(t)
+	creds := []byte("\x00user\x00pass")
+	auth := plainTextAuthenticator{username: "user", password: "pass"}
+
+	// any authenticator is allowed if the list is empty
+	resp, next, err := auth.Challenge([]byte("com.example.CustomAuthenticator"))
+	is.NoErr(err)
+	is.Equal(resp, creds)
+	is.Equal(next, nil)
+
+	// the DSE authenticator negotiates the mechanism first
+	resp, next, err = auth.Challenge([]byte(dseAuthenticator))
+	is.NoErr(err)
+	is.Equal(resp, []byte("PLAIN"))
+	resp, _, err = next.Challenge([]byte("PLAIN-START"))
+	is.NoErr(err)
+	is.Equal(resp, creds)
+
+	auth.allowedAuthenticators = []string{dseAuthenticator}
+	_, _, err = auth.Challenge([]byte("com.example.CustomAuthenticator"))
+	is.True(err != nil)
+}
@@ -0,0 +1,126 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/hamba/avro/v2"
+)
+
+// parseAvroSchemas parses the configured Avro schemas used to decode raw keys and payloads, a schema is nil if it's
+// not configured.
+func (d *DestinationConfig) parseAvroSchemas() (key, payload *avro.RecordSchema, err error) {
+	key, err = parseAvroRecordSchema("avro.keySchema", d.AvroKeySchema)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload, err = parseAvroRecordSchema("avro.payloadSchema", d.AvroPayloadSchema)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, payload, nil
+}
+
+func parseAvroRecordSchema(param, schema string) (*avro.RecordSchema, error) {
+	if schema == "" {
+		return nil, nil
+	}
+	parsed, err := avro.Parse(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", param, err)
+	}
+	rs, ok := parsed.(*avro.RecordSchema)
+	if !ok {
+		return nil, fmt.Errorf("invalid %s: should be a record schema, got %s", param, parsed.Type())
+	}
+	return rs, nil
+}
+
+// decodeAvro returns the record with its raw key and payload decoded from Avro into structured data. The schema is
+// the one referenced by the opencdc.key.schema.* or opencdc.payload.schema.* metadata, or the configured schema.
+// Raw data without a schema is left as is. Values are converted into the types of the schema fields, records are
+// decoded as maps so they're written into UDTs, arrays into lists and maps into maps.
+//
+// Note that the schema extraction middleware of the SDK, enabled by default, already decodes raw data that references
+// a schema before it's passed to the destination.
+func (d *Destination) decodeAvro(ctx context.Context, record opencdc.Record) (opencdc.Record, error) {
+	if raw, ok := record.Key.(opencdc.RawData); ok {
+		rs, err := d.avroSchema(ctx, record.Metadata.GetKeySchemaSubject, record.Metadata.GetKeySchemaVersion, d.avroKeySchema)
+		if err != nil {
+			return record, err
+		}
+		if rs != nil {
+			record.Key, err = decodeAvroData(raw, rs)
+			if err != nil {
+				return record, fmt.Errorf("%w: error while decoding the key: %w", errInvalidRecord, err)
+			}
+		}
+	}
+
+	for _, data := range []*opencdc.Data{&record.Payload.Before, &record.Payload.After} {
+		raw, ok := (*data).(opencdc.RawData)
+		if !ok || len(raw) == 0 {
+			continue
+		}
+		rs, err := d.avroSchema(ctx, record.Metadata.GetPayloadSchemaSubject, record.Metadata.GetPayloadSchemaVersion, d.avroPayloadSchema)
+		if err != nil {
+			return record, err
+		}
+		if rs == nil {
+			return record, nil
+		}
+		*data, err = decodeAvroData(raw, rs)
+		if err != nil {
+			return record, fmt.Errorf("%w: error while decoding the payload: %w", errInvalidRecord, err)
+		}
+	}
+	return record, nil
+}
+
+// avroSchema returns the schema referenced by the metadata, or the configured schema if the metadata doesn't
+// reference one.
+func (d *Destination) avroSchema(
+	ctx context.Context,
+	subject func() (string, error),
+	version func() (int, error),
+	configured *avro.RecordSchema,
+) (*avro.RecordSchema, error) {
+	s, err := subject()
+	if errors.Is(err, opencdc.ErrMetadataFieldNotFound) {
+		return configured, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidRecord, err)
+	}
+	v, err := version()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidRecord, err)
+	}
+	return d.fetchSchema(ctx, s, v)
+}
+
+// decodeAvroData decodes the Avro binary encoded data into structured data typed with the schema.
+func decodeAvroData(raw opencdc.RawData, rs *avro.RecordSchema) (opencdc.StructuredData, error) {
+	var decoded map[string]interface{}
+	err := avro.Unmarshal(rs, raw, &decoded)
+	if err != nil {
+		return nil, err
+	}
+	return typeStructuredData(decoded, rs)
+}
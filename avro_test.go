@@ -0,0 +1,152 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdkschema "github.com/conduitio/conduit-connector-sdk/schema"
+	"github.com/gocql/gocql"
+	"github.com/hamba/avro/v2"
+	"github.com/matryer/is"
+	"gopkg.in/inf.v0"
+)
+
+func TestDestinationConfig_ParseAvroSchemas(t *testing.T) {
+	testCases := []struct {
+		name    string
+		key     string
+		payload string
+		wantErr bool
+	}{
+		{name: "not configured"},
+		{name: "valid schemas", key: testKeySchema, payload: testPayloadSchema},
+		{name: "invalid schema", payload: `{"type": "record"}`, wantErr: true},
+		{name: "not a record schema", key: `"string"`, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			cfg := DestinationConfig{AvroKeySchema: tc.key, AvroPayloadSchema: tc.payload}
+			key, payload, err := cfg.parseAvroSchemas()
+			is.Equal(err != nil, tc.wantErr)
+			if !tc.wantErr {
+				is.Equal(key != nil, tc.key != "")
+				is.Equal(payload != nil, tc.payload != "")
+			}
+		})
+	}
+}
+
+func TestDestination_DecodeAvro(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	keySchema := parseTestSchema(t, testKeySchema)
+	payloadSchema := parseTestSchema(t, testPayloadSchema)
+
+	key, err := avro.Marshal(keySchema, map[string]interface{}{"id": int64(1)})
+	is.NoErr(err)
+	payload, err := avro.Marshal(payloadSchema, map[string]interface{}{
+		"name":    "john",
+		"age":     22,
+		"created": time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		"uid":     "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		"balance": big.NewRat(1250, 100),
+		"tags":    []int64{1, 2},
+	})
+	is.NoErr(err)
+
+	d := Destination{avroKeySchema: keySchema, avroPayloadSchema: payloadSchema}
+	got, err := d.decodeAvro(ctx, opencdc.Record{
+		Operation: opencdc.OperationUpdate,
+		Key:       opencdc.RawData(key),
+		Payload:   opencdc.Change{After: opencdc.RawData(payload)},
+	})
+	is.NoErr(err)
+	is.Equal(got.Key, opencdc.StructuredData{"id": int64(1)})
+	after := got.Payload.After.(opencdc.StructuredData)
+	is.Equal(after["name"], "john")
+	is.Equal(after["age"], int32(22))
+	is.Equal(after["created"], time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	is.Equal(after["balance"].(*inf.Dec).String(), "12.50")
+	is.Equal(after["tags"], []interface{}{int64(1), int64(2)})
+	is.Equal(got.Payload.Before, nil)
+
+	// the schema referenced by the metadata takes precedence over the configured schema
+	uidSchema := `{"type": "record", "name": "k", "fields": [{"name": "uid", "type": "string"}]}`
+	s, err := sdkschema.Create(ctx, sdkschema.TypeAvro, "ids-key", []byte(uidSchema))
+	is.NoErr(err)
+	uid, err := avro.Marshal(parseTestSchema(t, uidSchema), map[string]interface{}{"uid": "abc"})
+	is.NoErr(err)
+	rec := opencdc.Record{Metadata: opencdc.Metadata{}, Key: opencdc.RawData(uid)}
+	sdkschema.AttachKeySchemaToRecord(rec, s)
+	got, err = d.decodeAvro(ctx, rec)
+	is.NoErr(err)
+	is.Equal(got.Key, opencdc.StructuredData{"uid": "abc"})
+
+	// raw data without a schema is left as is
+	got, err = (&Destination{}).decodeAvro(ctx, opencdc.Record{Key: opencdc.RawData(key)})
+	is.NoErr(err)
+	is.Equal(got.Key, opencdc.RawData(key))
+
+	_, err = d.decodeAvro(ctx, opencdc.Record{Key: opencdc.RawData(key), Payload: opencdc.Change{After: opencdc.RawData("x")}})
+	is.True(errors.Is(err, errInvalidRecord))
+}
+
+func TestDecodeAvroData_NestedRecord(t *testing.T) {
+	is := is.New(t)
+	rs := parseTestSchema(t, `{
+		"type": "record",
+		"name": "user",
+		"fields": [
+			{"name": "name", "type": "string"},
+			{"name": "address", "type": {
+				"type": "record",
+				"name": "address",
+				"fields": [
+					{"name": "city", "type": "string"},
+					{"name": "since", "type": {"type": "int", "logicalType": "date"}}
+				]
+			}}
+		]
+	}`)
+	since := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	raw, err := avro.Marshal(rs, map[string]interface{}{
+		"name":    "john",
+		"address": map[string]interface{}{"city": "Paris", "since": since},
+	})
+	is.NoErr(err)
+
+	data, err := decodeAvroData(raw, rs)
+	is.NoErr(err)
+	is.Equal(data["address"], map[string]interface{}{"city": "Paris", "since": since})
+
+	// records are written into UDTs
+	udt := gocql.UDTTypeInfo{
+		NativeType: gocql.NewNativeType(4, gocql.TypeUDT, ""),
+		Name:       "address",
+		Elements: []gocql.UDTField{
+			{Name: "city", Type: gocql.NewNativeType(4, gocql.TypeText, "")},
+			{Name: "since", Type: gocql.NewNativeType(4, gocql.TypeDate, "")},
+		},
+	}
+	_, err = gocql.Marshal(udt, data["address"])
+	is.NoErr(err)
+}
@@ -0,0 +1,153 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+const (
+	BucketSizeHour  = "hour"
+	BucketSizeDay   = "day"
+	BucketSizeMonth = "month"
+)
+
+// bucketFormats are the default formats of the bucket for each size.
+var bucketFormats = map[string]string{
+	BucketSizeHour:  "2006-01-02T15",
+	BucketSizeDay:   "2006-01-02",
+	BucketSizeMonth: "2006-01",
+}
+
+// bucketEnabled returns true if a time bucket column is added to the key of each record.
+func (d *DestinationConfig) bucketEnabled() bool {
+	return d.BucketColumn != ""
+}
+
+func (d *DestinationConfig) validateBucket() error {
+	if !d.bucketEnabled() {
+		return nil
+	}
+	if !cqlIdentifierRegex.MatchString(d.BucketColumn) {
+		return fmt.Errorf("invalid bucket.column %q, should be a valid column name", d.BucketColumn)
+	}
+	if d.BucketField == d.BucketColumn {
+		return fmt.Errorf("bucket.field and bucket.column should be different")
+	}
+	// consecutive buckets should be formatted differently, otherwise the format merges them into larger buckets
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if start.Format(d.bucketFormat()) == d.nextBucket(start).Format(d.bucketFormat()) {
+		return fmt.Errorf("bucket.format %q doesn't distinguish the buckets of size %s", d.BucketFormat, d.BucketSize)
+	}
+	return nil
+}
+
+// bucketStart returns the start of the bucket containing the timestamp, in UTC.
+func (d *DestinationConfig) bucketStart(ts time.Time) time.Time {
+	ts = ts.UTC()
+	switch d.BucketSize {
+	case BucketSizeHour:
+		return ts.Truncate(time.Hour)
+	case BucketSizeMonth:
+		return time.Date(ts.Year(), ts.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// nextBucket returns the start of the bucket following the bucket starting at start.
+func (d *DestinationConfig) nextBucket(start time.Time) time.Time {
+	switch d.BucketSize {
+	case BucketSizeHour:
+		return start.Add(time.Hour)
+	case BucketSizeMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// bucketFormat returns the configured format of the bucket, or the default format of the bucket size. The timestamp
+// is truncated to the start of its bucket before it's formatted, so the format only changes how buckets are written.
+func (d *DestinationConfig) bucketFormat() string {
+	if d.BucketFormat != "" {
+		return d.BucketFormat
+	}
+	return bucketFormats[d.BucketSize]
+}
+
+// addBucket returns the record with the time bucket column added to its key. The bucket is derived from the
+// timestamp field, looked up in the key, then in the payload after and before, or from the opencdc.readAt metadata
+// if no field is configured. The read time of updates and deletes is later than the one of the record that created
+// the row, so they can't be bucketed without a field. The key is copied, so the original record is left unchanged.
+func (d *Destination) addBucket(record opencdc.Record) (opencdc.Record, error) {
+	key, ok := record.Key.(opencdc.StructuredData)
+	if !ok {
+		return record, fmt.Errorf("%w: key should be structured data to add the time bucket", errInvalidRecord)
+	}
+
+	var ts time.Time
+	if d.config.BucketField == "" {
+		if record.Operation == opencdc.OperationUpdate || record.Operation == opencdc.OperationDelete {
+			return record, fmt.Errorf("%w: bucket.field is required to find the time bucket of %s records", errInvalidRecord, record.Operation)
+		}
+		var err error
+		ts, err = record.Metadata.GetReadAt()
+		if err != nil {
+			return record, fmt.Errorf("%w: %w", errInvalidRecord, err)
+		}
+	} else {
+		value, ok := fieldValue(d.config.BucketField, record.Key, record.Payload.After, record.Payload.Before)
+		if !ok {
+			return record, fmt.Errorf("%w: timestamp field %q for the time bucket not found", errInvalidRecord, d.config.BucketField)
+		}
+		var err error
+		ts, err = parseTimestamp(value)
+		if err != nil {
+			return record, fmt.Errorf("%w: invalid timestamp field %q for the time bucket: %w", errInvalidRecord, d.config.BucketField, err)
+		}
+	}
+
+	bucketed := copyStructuredData(key)
+	bucketed[d.config.BucketColumn] = d.config.bucketStart(ts).Format(d.config.bucketFormat())
+	record.Key = bucketed
+	return record, nil
+}
+
+// parseTimestamp parses a timestamp that is either a time.Time, a string in RFC3339 format, or a number of
+// milliseconds since epoch.
+func parseTimestamp(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	case json.Number:
+		ms, err := v.Int64()
+		return time.UnixMilli(ms), err
+	case int:
+		return time.UnixMilli(int64(v)), nil
+	case int64:
+		return time.UnixMilli(v), nil
+	case float64:
+		return time.UnixMilli(int64(v)), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported type %T, should be a timestamp in RFC3339 format or milliseconds since epoch", value)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/matryer/is"
+)
+
+func TestDestination_AddBucket(t *testing.T) {
+	ts := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	testCases := []struct {
+		name    string
+		config  DestinationConfig
+		record  opencdc.Record
+		want    string
+		wantErr bool
+	}{{
+		name:   "day bucket from payload",
+		config: DestinationConfig{BucketColumn: "bucket", BucketField: "created_at", BucketSize: BucketSizeDay},
+		record: opencdc.Record{
+			Key:     opencdc.StructuredData{"id": 1},
+			Payload: opencdc.Change{After: opencdc.StructuredData{"created_at": "2024-03-15T10:30:00Z"}},
+		},
+		want: "2024-03-15",
+	}, {
+		name:   "hour bucket from milliseconds in key",
+		config: DestinationConfig{BucketColumn: "bucket", BucketField: "created_at", BucketSize: BucketSizeHour},
+		record: opencdc.Record{
+			Key: opencdc.StructuredData{"id": 1, "created_at": ts.UnixMilli()},
+		},
+		want: "2024-03-15T10",
+	}, {
+		name:   "month bucket from payload before",
+		config: DestinationConfig{BucketColumn: "bucket", BucketField: "created_at", BucketSize: BucketSizeMonth},
+		record: opencdc.Record{
+			Key:     opencdc.StructuredData{"id": 1},
+			Payload: opencdc.Change{Before: opencdc.StructuredData{"created_at": ts}},
+		},
+		want: "2024-03",
+	}, {
+		name:   "custom format from read at metadata",
+		config: DestinationConfig{BucketColumn: "bucket", BucketSize: BucketSizeDay, BucketFormat: "20060102"},
+		record: opencdc.Record{
+			Metadata: opencdc.Metadata{opencdc.MetadataReadAt: "1710498600000000000"},
+			Key:      opencdc.StructuredData{"id": 1},
+		},
+		want: "20240315",
+	}, {
+		name:   "custom format truncated to the bucket size",
+		config: DestinationConfig{BucketColumn: "bucket", BucketField: "created_at", BucketSize: BucketSizeDay, BucketFormat: "2006-01-02T15"},
+		record: opencdc.Record{
+			Key: opencdc.StructuredData{"id": 1, "created_at": ts},
+		},
+		want: "2024-03-15T00",
+	}, {
+		name:   "update without field",
+		config: DestinationConfig{BucketColumn: "bucket", BucketSize: BucketSizeDay},
+		record: opencdc.Record{
+			Operation: opencdc.OperationUpdate,
+			Metadata:  opencdc.Metadata{opencdc.MetadataReadAt: "1710498600000000000"},
+			Key:       opencdc.StructuredData{"id": 1},
+		},
+		wantErr: true,
+	}, {
+		name:   "missing field",
+		config: DestinationConfig{BucketColumn: "bucket", BucketField: "created_at", BucketSize: BucketSizeDay},
+		record: opencdc.Record{
+			Key: opencdc.StructuredData{"id": 1},
+		},
+		wantErr: true,
+	}, {
+		name:   "invalid timestamp",
+		config: DestinationConfig{BucketColumn: "bucket", BucketField: "created_at", BucketSize: BucketSizeDay},
+		record: opencdc.Record{
+			Key: opencdc.StructuredData{"id": 1, "created_at": "yesterday"},
+		},
+		wantErr: true,
+	}}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			d := Destination{config: tt.config}
+			got, err := d.addBucket(tt.record)
+			if tt.wantErr {
+				is.True(errors.Is(err, errInvalidRecord))
+				return
+			}
+			is.NoErr(err)
+			is.Equal(got.Key.(opencdc.StructuredData)["bucket"], tt.want)
+			// the original key is left unchanged
+			_, ok := tt.record.Key.(opencdc.StructuredData)["bucket"]
+			is.True(!ok)
+		})
+	}
+}
+
+func TestConfig_Bucket(t *testing.T) {
+	testCases := []struct {
+		name    string
+		size    string
+		format  string
+		wantErr bool
+	}{
+		{name: "default format", size: BucketSizeHour},
+		{name: "finer format", size: BucketSizeMonth, format: "2006-01-02"},
+		{name: "coarser format", size: BucketSizeHour, format: "2006-01-02", wantErr: true},
+		{name: "format without the month", size: BucketSizeMonth, format: "2006", wantErr: true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			cfg := DestinationConfig{BucketColumn: "bucket", BucketSize: tt.size, BucketFormat: tt.format}
+			err := cfg.validateBucket()
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+)
+
+const (
+	// ModeMirror applies the records to the table, so it mirrors the source.
+	ModeMirror = "mirror"
+	// ModeChangelog appends each record as an event to the table.
+	ModeChangelog = "changelog"
+)
+
+const (
+	createChangelogTableQuery = `CREATE TABLE IF NOT EXISTS %s (
+		key blob,
+		id timeuuid,
+		operation text,
+		before blob,
+		after blob,
+		metadata map<text, text>,
+		position blob,
+		PRIMARY KEY ((key), id)
+	) WITH CLUSTERING ORDER BY (id ASC)`
+	insertChangelogQuery = "INSERT INTO %s (key, id, operation, before, after, metadata, position) VALUES (?, ?, ?, ?, ?, ?, ?)"
+)
+
+// writeChangelog appends the record as an event to the changelog table, the table is created the first time a record
+// is written to it.
+func (d *Destination) writeChangelog(ctx context.Context, record opencdc.Record) error {
+	table, err := d.getTableName(record)
+	if err != nil {
+		return err
+	}
+	if !d.changelogTables[table] && !d.config.DryRun {
+		err = d.session.Query(fmt.Sprintf(createChangelogTableQuery, table)).Exec()
+		if err != nil {
+			return fmt.Errorf("error while creating the changelog table: %w", err)
+		}
+		d.changelogTables[table] = true
+	}
+
+	stmt, err := d.queryBuilder.BuildChangelogQuery(record, table, gocql.TimeUUID())
+	if err != nil {
+		return err
+	}
+	// the event id is generated once, so retrying the query doesn't append the event twice
+	err = d.exec(ctx, record, table, stmt)
+	if err != nil {
+		return fmt.Errorf("error while appending the changelog event: %w", err)
+	}
+	return nil
+}
+
+// BuildChangelogQuery takes a record, and returns the statement that appends it as an event with the given id to a
+// changelog table. The key and payload are stored as blobs, serialized as JSON if they are structured, or written as
+// is if they are raw data, which doesn't have to be valid UTF-8, ex: Avro.
+func (q *QueryBuilder) BuildChangelogQuery(rec opencdc.Record, table string, id gocql.UUID) (Statement, error) {
+	key := dataBytes(rec.Key)
+	if len(key) == 0 {
+		return Statement{}, fmt.Errorf("%w: key is required in changelog mode", errInvalidRecord)
+	}
+	return Statement{
+		CQL: fmt.Sprintf(insertChangelogQuery, table),
+		Values: []interface{}{
+			key,
+			id,
+			rec.Operation.String(),
+			dataBytes(rec.Payload.Before),
+			dataBytes(rec.Payload.After),
+			map[string]string(rec.Metadata),
+			[]byte(rec.Position),
+		},
+		Columns:   []string{"key", "id", "operation", "before", "after", "metadata", "position"},
+		Operation: rec.Operation,
+	}, nil
+}
+
+// dataBytes returns the data serialized as bytes, or nil if it's nil.
+func dataBytes(data opencdc.Data) []byte {
+	if data == nil {
+		return nil
+	}
+	return data.Bytes()
+}
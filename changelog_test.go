@@ -0,0 +1,64 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+)
+
+func TestQueryBuilder_Changelog(t *testing.T) {
+	is := is.New(t)
+	builder := QueryBuilder{}
+	id := gocql.TimeUUID()
+	rec := opencdc.Record{
+		Position:  opencdc.Position("pos-1"),
+		Operation: opencdc.OperationUpdate,
+		Metadata:  opencdc.Metadata{"opencdc.collection": "users"},
+		Key:       opencdc.StructuredData{"id": 1},
+		Payload: opencdc.Change{
+			Before: opencdc.RawData("john"),
+			After:  opencdc.StructuredData{"name": "jane"},
+		},
+	}
+
+	stmt, err := builder.BuildChangelogQuery(rec, "ks.users_changelog", id)
+	is.NoErr(err)
+	is.Equal(stmt.CQL, "INSERT INTO ks.users_changelog (key, id, operation, before, after, metadata, position) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	is.Equal(stmt.Values, []interface{}{
+		[]byte(`{"id":1}`),
+		id,
+		"update",
+		[]byte("john"),
+		[]byte(`{"name":"jane"}`),
+		map[string]string{"opencdc.collection": "users"},
+		[]byte("pos-1"),
+	})
+
+	// raw data is stored as is, even if it's not valid UTF-8
+	rec.Payload.After = opencdc.RawData{0xff, 0xfe}
+	stmt, err = builder.BuildChangelogQuery(rec, "ks.users_changelog", id)
+	is.NoErr(err)
+	is.Equal(stmt.Values[4], []byte{0xff, 0xfe})
+
+	// the key is the partition key of the changelog
+	rec.Key = nil
+	_, err = builder.BuildChangelogQuery(rec, "ks.users_changelog", id)
+	is.True(errors.Is(err, errInvalidRecord))
+}
@@ -0,0 +1,228 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+)
+
+const (
+	createCheckpointTableQuery = `CREATE TABLE IF NOT EXISTS %s (
+		target_table text,
+		partition text,
+		position blob,
+		updated_at timestamp,
+		PRIMARY KEY ((target_table, partition))
+	)`
+	selectCheckpointQuery = "SELECT position FROM %s WHERE target_table = ? AND partition = ?"
+	updateCheckpointQuery = "UPDATE %s SET position = ?, updated_at = toTimestamp(now()) WHERE target_table = ? AND partition = ?"
+)
+
+const (
+	// CheckpointPositionOrderBytes compares the positions byte by byte.
+	CheckpointPositionOrderBytes = "bytes"
+	// CheckpointPositionOrderNumber compares the positions as decimal numbers, ex: offsets.
+	CheckpointPositionOrderNumber = "number"
+	// CheckpointPositionOrderField compares the positions as JSON objects, by the number in checkpoint.positionField.
+	CheckpointPositionOrderField = "field"
+)
+
+// checkpointEnabled returns true if the last applied position is stored for each partition to skip replayed records.
+func (d *DestinationConfig) checkpointEnabled() bool {
+	return d.CheckpointTable != ""
+}
+
+func (d *DestinationConfig) validateCheckpointTable() error {
+	if !d.checkpointEnabled() {
+		return nil
+	}
+	keyspace, table, ok := strings.Cut(d.CheckpointTable, ".")
+	if !ok || !cqlIdentifierRegex.MatchString(keyspace) || !cqlIdentifierRegex.MatchString(table) {
+		return fmt.Errorf("invalid checkpoint.table %q, should be in the format keyspace.table", d.CheckpointTable)
+	}
+	// positions are opaque, so how they are ordered can't be guessed
+	switch d.CheckpointPositionOrder {
+	case "":
+		return fmt.Errorf("checkpoint.positionOrder should be provided when checkpoint.table is set")
+	case CheckpointPositionOrderField:
+		if d.CheckpointPositionField == "" {
+			return fmt.Errorf("checkpoint.positionField should be provided for the %s position order", CheckpointPositionOrderField)
+		}
+	}
+	return nil
+}
+
+// comparePositions compares two positions using the configured position order, it returns -1, 0 or 1 if a is lower
+// than, equal to, or greater than b.
+func (d *DestinationConfig) comparePositions(a, b []byte) (int, error) {
+	if d.CheckpointPositionOrder == CheckpointPositionOrderBytes {
+		return bytes.Compare(a, b), nil
+	}
+	na, err := d.positionNumber(a)
+	if err != nil {
+		return 0, err
+	}
+	nb, err := d.positionNumber(b)
+	if err != nil {
+		return 0, err
+	}
+	return na.Cmp(nb), nil
+}
+
+// positionNumber returns the number the position is ordered by, the position itself with the number order, or its
+// checkpoint.positionField field with the field order.
+func (d *DestinationConfig) positionNumber(position []byte) (*big.Rat, error) {
+	value := strings.TrimSpace(string(position))
+	if d.CheckpointPositionOrder == CheckpointPositionOrderField {
+		var data map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(position))
+		dec.UseNumber()
+		if err := dec.Decode(&data); err != nil {
+			return nil, fmt.Errorf("position %q is not a JSON object: %w", position, err)
+		}
+		field, ok := lookupPath(opencdc.StructuredData(data), d.CheckpointPositionField)
+		if !ok {
+			return nil, fmt.Errorf("position %q doesn't contain the field %q", position, d.CheckpointPositionField)
+		}
+		value = fmt.Sprint(field)
+	}
+	n, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("position %q is not ordered by a number: %q", position, value)
+	}
+	return n, nil
+}
+
+// hasCounterColumn returns true if the table has a counter column. Counter updates can't be executed in a logged
+// batch, so they can't be batched with the checkpoint update.
+func hasCounterColumn(meta *gocql.TableMetadata) bool {
+	for _, c := range meta.Columns {
+		if c.Type != nil && c.Type.Type() == gocql.TypeCounter {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Destination) createCheckpointTable() error {
+	err := d.session.Query(fmt.Sprintf(createCheckpointTableQuery, d.config.CheckpointTable)).Exec()
+	if err != nil {
+		return fmt.Errorf("error while creating the checkpoint table: %w", err)
+	}
+	return nil
+}
+
+// isReplayed returns true if the record was already applied, meaning that its position is lower than or equal to the
+// last position applied to the same partition. Positions are compared using the configured position order.
+func (d *Destination) isReplayed(ctx context.Context, record opencdc.Record, table string) (bool, error) {
+	if len(record.Position) == 0 {
+		return false, fmt.Errorf("%w: the record position is required to check the checkpoint", errInvalidRecord)
+	}
+	partition, err := d.checkpointPartition(record, table)
+	if err != nil {
+		return false, err
+	}
+	var position []byte
+	err = d.session.Query(fmt.Sprintf(selectCheckpointQuery, d.config.CheckpointTable), table, partition).
+		WithContext(ctx).Idempotent(true).Scan(&position)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error while reading the checkpoint: %w", err)
+	}
+	cmp, err := d.config.comparePositions(record.Position, position)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", errInvalidRecord, err)
+	}
+	return cmp <= 0, nil
+}
+
+// execWithCheckpoint executes the statements and updates the checkpoint of the record partition in a logged batch,
+// so either both or none are applied. The statements should be unconditional, since lightweight transactions can't
+// be batched with statements on other tables, and counter tables are rejected since counter updates can't be executed
+// in a logged batch.
+func (d *Destination) execWithCheckpoint(ctx context.Context, record opencdc.Record, table string, stmts []Statement) error {
+	if d.queryBuilder.tables != nil {
+		if meta, err := d.queryBuilder.tables(table); err == nil && hasCounterColumn(meta) {
+			return fmt.Errorf("table %s has counter columns, counters are unsupported with checkpoint.table since counter updates can't be batched with the checkpoint update", table)
+		}
+	}
+	partition, err := d.checkpointPartition(record, table)
+	if err != nil {
+		return err
+	}
+	stmts = append(stmts, Statement{
+		CQL:    fmt.Sprintf(updateCheckpointQuery, d.config.CheckpointTable),
+		Values: []interface{}{[]byte(record.Position), table, partition},
+	})
+	if d.config.DryRun {
+		return d.renderDryRun(ctx, record, stmts...)
+	}
+	// the batch is idempotent, since it applies the same values if it's retried, unless a statement isn't
+	batch := d.batch(ctx, record, table)
+	for _, stmt := range stmts {
+		batch.Entries = append(batch.Entries, gocql.BatchEntry{Stmt: stmt.CQL, Args: stmt.Values, Idempotent: !stmt.NonIdempotent})
+	}
+	return d.session.ExecuteBatch(batch)
+}
+
+// checkpointPartition returns the partition of the record checkpoint, it's the JSON representation of the partition
+// key fields of the record key, or of the whole key if the table schema is unknown. Changelog tables are partitioned by
+// the whole key, as it's stored in the table.
+func (d *Destination) checkpointPartition(record opencdc.Record, table string) (string, error) {
+	if d.config.Mode == ModeChangelog {
+		// the partition column is text, so raw keys that are not valid UTF-8 are base64 encoded
+		key := dataBytes(record.Key)
+		if !utf8.Valid(key) {
+			return base64.StdEncoding.EncodeToString(key), nil
+		}
+		return string(key), nil
+	}
+	key := record.Key.(opencdc.StructuredData)
+	if d.queryBuilder.tables != nil {
+		if meta, err := d.queryBuilder.tables(table); err == nil {
+			key = partitionKey(meta, key)
+		}
+	}
+	// maps are marshaled with sorted keys, so the partition is deterministic
+	partition, err := json.Marshal(key)
+	if err != nil {
+		return "", fmt.Errorf("%w: error while marshaling the partition key: %w", errInvalidRecord, err)
+	}
+	return string(partition), nil
+}
+
+// partitionKey returns the fields of the key that are part of the table partition key.
+func partitionKey(meta *gocql.TableMetadata, key opencdc.StructuredData) opencdc.StructuredData {
+	partition := make(opencdc.StructuredData, len(meta.PartitionKey))
+	for _, c := range meta.PartitionKey {
+		if v, ok := key[c.Name]; ok {
+			partition[c.Name] = v
+		}
+	}
+	return partition
+}
@@ -0,0 +1,127 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+)
+
+func TestConfig_CheckpointTable(t *testing.T) {
+	is := is.New(t)
+	cfg := DestinationConfig{CheckpointTable: "checkpoints", CheckpointPositionOrder: CheckpointPositionOrderNumber}
+	is.True(cfg.validateConfig() != nil)
+	cfg.CheckpointTable = "ks.checkpoints"
+	is.NoErr(cfg.validateConfig())
+	is.True(cfg.checkpointEnabled())
+
+	// the position order is required
+	cfg.CheckpointPositionOrder = ""
+	is.True(cfg.validateConfig() != nil)
+	cfg.CheckpointPositionOrder = CheckpointPositionOrderField
+	is.True(cfg.validateConfig() != nil)
+	cfg.CheckpointPositionField = "offset"
+	is.NoErr(cfg.validateConfig())
+}
+
+func TestConfig_ComparePositions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		order   string
+		a, b    string
+		want    int
+		wantErr bool
+	}{
+		{name: "bytes", order: CheckpointPositionOrderBytes, a: "10", b: "9", want: -1},
+		{name: "number", order: CheckpointPositionOrderNumber, a: "10", b: "9", want: 1},
+		{name: "equal numbers", order: CheckpointPositionOrderNumber, a: "10", b: "10.0", want: 0},
+		{name: "not a number", order: CheckpointPositionOrderNumber, a: "10", b: "abc", wantErr: true},
+		{name: "field", order: CheckpointPositionOrderField, a: `{"partition":1,"offset":10}`, b: `{"offset":9,"partition":1}`, want: 1},
+		{name: "string field", order: CheckpointPositionOrderField, a: `{"offset":"8"}`, b: `{"offset":9}`, want: -1},
+		{name: "missing field", order: CheckpointPositionOrderField, a: `{"offset":10}`, b: `{"lsn":9}`, wantErr: true},
+		{name: "not JSON", order: CheckpointPositionOrderField, a: `{"offset":10}`, b: "9", wantErr: true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			cfg := DestinationConfig{CheckpointPositionOrder: tt.order, CheckpointPositionField: "offset"}
+			got, err := cfg.comparePositions([]byte(tt.a), []byte(tt.b))
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(got, tt.want)
+		})
+	}
+}
+
+func TestDestination_CheckpointCounterTable(t *testing.T) {
+	is := is.New(t)
+	d := Destination{
+		config: DestinationConfig{CheckpointTable: "ks.checkpoints", CheckpointPositionOrder: CheckpointPositionOrderBytes},
+		queryBuilder: QueryBuilder{tables: func(string) (*gocql.TableMetadata, error) {
+			return &gocql.TableMetadata{Columns: map[string]*gocql.ColumnMetadata{
+				"id":    {Name: "id", Type: gocql.NewNativeType(4, gocql.TypeInt, "")},
+				"views": {Name: "views", Type: gocql.NewNativeType(4, gocql.TypeCounter, "")},
+			}}, nil
+		}},
+	}
+	rec := opencdc.Record{Position: opencdc.Position("1"), Key: opencdc.StructuredData{"id": 1}}
+	err := d.execWithCheckpoint(context.Background(), rec, "ks.views", []Statement{{CQL: "UPDATE ks.views SET views = views + 1 WHERE id = ?"}})
+	is.True(err != nil)
+}
+
+func TestDestination_CheckpointPartition(t *testing.T) {
+	is := is.New(t)
+	rec := opencdc.Record{
+		Key: opencdc.StructuredData{"tenant": "acme", "id": 1, "created": 3, "seq": 2},
+	}
+
+	// only the partition key columns are used if the schema is known
+	d := Destination{queryBuilder: QueryBuilder{tables: testTableMetadata}}
+	partition, err := d.checkpointPartition(rec, "ks.t")
+	is.NoErr(err)
+	is.Equal(partition, `{"id":1,"tenant":"acme"}`)
+
+	d = Destination{}
+	partition, err = d.checkpointPartition(rec, "ks.t")
+	is.NoErr(err)
+	is.Equal(partition, `{"created":3,"id":1,"seq":2,"tenant":"acme"}`)
+
+	// changelog tables are partitioned by the whole key, which can be raw data
+	d = Destination{config: DestinationConfig{Mode: ModeChangelog}, queryBuilder: QueryBuilder{tables: testTableMetadata}}
+	rec.Key = opencdc.RawData("acme-1")
+	partition, err = d.checkpointPartition(rec, "ks.t")
+	is.NoErr(err)
+	is.Equal(partition, "acme-1")
+}
+
+func TestQueryBuilder_Unconditional(t *testing.T) {
+	is := is.New(t)
+	builder := QueryBuilder{unconditional: true}
+	rec := opencdc.Record{
+		Key: opencdc.StructuredData{"id": 1},
+		Payload: opencdc.Change{
+			After: opencdc.StructuredData{"name": "john"},
+		},
+	}
+	is.Equal(builder.BuildInsertQuery(rec, "ks.t").CQL, "INSERT INTO ks.t (name, id) VALUES (?, ?)")
+	is.Equal(builder.BuildUpdateQuery(rec, "ks.t").CQL, "UPDATE ks.t SET name = ? WHERE id = ?")
+}
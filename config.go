@@ -15,58 +15,501 @@
 package cassandra
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"regexp"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/hamba/avro/v2"
 )
 
 //go:generate paramgen -output=paramgen_dest.go DestinationConfig
 
 type DestinationConfig struct {
 	// The keyspace name that has the table (similar to a database in a relational database system).
+	// It can contain a Go template that is executed for each record to determine the keyspace, ex: `tenant_{{ .Key.tenant_id }}`.
 	Keyspace string `json:"keyspace" validate:"required"`
-	// The table name.
+	// The table name. It can contain a Go template that is executed for each record to determine the table,
+	// ex: `{{ index .Metadata "opencdc.collection" }}_v2`.
 	Table string `json:"table" validate:"required"`
 	// Comma separated list of Cassandra nodes' addresses (at least one), ex: 127.0.0.1:9042,127.0.0.2:8080
+	// Nodes can also be discovered using DNS, either from an SRV record, ex: srv://_cql._tcp.cassandra.local, or from
+	// all the addresses of a hostname, ex: dns://cassandra-headless:9042
 	Nodes []string `json:"nodes" validate:"required"`
-	// Authentication mechanism used by Cassandra.
-	AuthMechanism string `json:"auth.mechanism" validate:"inclusion=none|basic" default:"none"`
-	// Username, only if basic auth is used.
+	// Authentication mechanism used by Cassandra, one of: none, basic, plain, token.
+	AuthMechanism string `json:"auth.mechanism" validate:"inclusion=none|basic|plain|token" default:"none"`
+	// Username, only if basic or plain auth is used.
 	AuthUsername string `json:"auth.basic.username"`
-	// Password, only if basic auth is used.
+	// Password, only if basic or plain auth is used.
 	AuthPassword string `json:"auth.basic.password"`
+	// Token, only if token auth is used.
+	AuthToken string `json:"auth.token"`
+	// Comma separated list of authenticator classes the connector is allowed to authenticate with, ex:
+	// com.datastax.bdp.cassandra.auth.DseAuthenticator. If empty, the basic and token mechanisms allow a default list
+	// of known password authenticators, and the plain mechanism allows any authenticator.
+	AuthAllowedAuthenticators []string `json:"auth.allowedAuthenticators"`
+	// Path to a file containing the credentials, one key=value pair per line where the key is one of username,
+	// password or token. The file is read each time a connection is opened, so rotated credentials are picked up
+	// when reconnecting. Credentials in the file take precedence over the other configurations.
+	AuthCredentialsFile string `json:"auth.credentialsFile"`
+	// Name of the environment variable containing the username, takes precedence over auth.basic.username.
+	AuthUsernameEnv string `json:"auth.usernameEnv"`
+	// Name of the environment variable containing the password, takes precedence over auth.basic.password.
+	AuthPasswordEnv string `json:"auth.passwordEnv"`
+	// Name of the environment variable containing the token, takes precedence over auth.token.
+	AuthTokenEnv string `json:"auth.tokenEnv"`
+
+	// Action taken when a record can't be marshalled into a CQL query, one of: fail, skip, deadletter.
+	ErrorsMarshal string `json:"errors.marshal" validate:"inclusion=fail|skip|deadletter" default:"fail"`
+	// Action taken when a record contains a column that is not defined in the table, one of: fail, skip, deadletter.
+	ErrorsUndefinedColumn string `json:"errors.undefinedColumn" validate:"inclusion=fail|skip|deadletter" default:"fail"`
+	// Action taken when a lightweight transaction is not applied (row already exists on insert, or doesn't exist on
+	// update), one of: fail, skip, deadletter. Writes don't use lightweight transactions with checkpoint.table, fanout
+	// tables or the debezium input format.
+	ErrorsLWT string `json:"errors.lwt" validate:"inclusion=fail|skip|deadletter" default:"skip"`
+	// Action taken when writing a record times out, one of: fail, skip, deadletter.
+	ErrorsTimeout string `json:"errors.timeout" validate:"inclusion=fail|skip|deadletter" default:"fail"`
+	// Fully qualified name (keyspace.table) of the dead-letter table, required if any error action is deadletter.
+	// The table is created if it doesn't exist.
+	ErrorsDeadLetterTable string `json:"errors.deadletter.table"`
+
+	// Maximum number of times a query that failed with a transient error is retried, retries are disabled if set to 0.
+	// Only idempotent queries are retried, lightweight transactions are never retried.
+	RetryMaxRetries int `json:"retry.maxRetries" validate:"greater-than=-1" default:"3"`
+	// Minimum time to wait before retrying a query, the wait time grows exponentially with each retry.
+	RetryMinBackoff time.Duration `json:"retry.minBackoff" default:"100ms"`
+	// Maximum time to wait before retrying a query.
+	RetryMaxBackoff time.Duration `json:"retry.maxBackoff" default:"10s"`
+	// Comma separated list of consistency levels used for each retry, ex: QUORUM,ONE. If set, retries downgrade the
+	// consistency level instead of using an exponential backoff.
+	RetryDowngradingConsistency []string `json:"retry.downgradingConsistency"`
+
+	// Policy used to select the host that coordinates a query, one of: roundRobin, dcAware, rackAware, tokenAware.
+	HostSelection string `json:"hostSelection" validate:"inclusion=roundRobin|dcAware|rackAware|tokenAware" default:"roundRobin"`
+	// Name of the local datacenter, required by the dcAware and rackAware host selection policies. With the tokenAware
+	// policy, it makes the fallback policy prefer hosts in the local datacenter.
+	LocalDC string `json:"localDC"`
+	// Name of the local rack, required by the rackAware host selection policy.
+	LocalRack string `json:"localRack"`
+	// Whether to shuffle the replicas picked by the tokenAware host selection policy, spreading the load between them.
+	ShuffleReplicas bool `json:"shuffleReplicas" default:"false"`
+	// Comma separated list of hosts the connector is allowed to connect to, other hosts discovered in the cluster are
+	// ignored, ex: 127.0.0.1,127.0.0.2:9042. A host with a port is only allowed on that port. All hosts are allowed if
+	// empty.
+	HostAllowList []string `json:"hostAllowList"`
+
+	// Timeout for establishing a connection to a node.
+	ConnectionConnectTimeout time.Duration `json:"connection.connectTimeout" default:"11s"`
+	// Timeout for a query to return a response.
+	ConnectionTimeout time.Duration `json:"connection.timeout" default:"11s"`
+	// Timeout for writing a query to a connection.
+	ConnectionWriteTimeout time.Duration `json:"connection.writeTimeout" default:"11s"`
+	// Number of connections opened to each node.
+	ConnectionNumConns int `json:"connection.numConns" validate:"greater-than=0" default:"2"`
+	// Native protocol version used to communicate with the cluster, 0 means it's discovered when connecting.
+	ConnectionProtoVersion int `json:"connection.protoVersion" validate:"inclusion=0|3|4" default:"0"`
+	// Compression algorithm used for the frames sent to the cluster, one of: none, snappy, lz4.
+	ConnectionCompression string `json:"connection.compression" validate:"inclusion=none|snappy|lz4" default:"none"`
+	// Policy used to reconnect to a node that went down, one of: constant, exponential.
+	ConnectionReconnectionPolicy string `json:"connection.reconnection.policy" validate:"inclusion=constant|exponential" default:"constant"`
+	// Maximum number of reconnection attempts to a node that went down.
+	ConnectionReconnectionMaxRetries int `json:"connection.reconnection.maxRetries" validate:"greater-than=-1" default:"3"`
+	// Interval between reconnection attempts, it's the initial interval for the exponential policy.
+	ConnectionReconnectionInterval time.Duration `json:"connection.reconnection.interval" default:"1s"`
+	// Maximum interval between reconnection attempts, only used by the exponential policy.
+	ConnectionReconnectionMaxInterval time.Duration `json:"connection.reconnection.maxInterval" default:"1m"`
+	// Whether to skip discovering the cluster nodes when connecting, and only use the configured nodes. Needed when
+	// the nodes advertise addresses that are not reachable from the connector, ex: behind NAT or in Kubernetes.
+	ConnectionDisableInitialHostLookup bool `json:"connection.disableInitialHostLookup" default:"false"`
+
+	// Comma separated list of address translations in the format internal=external, used to reach nodes that
+	// advertise addresses not reachable by the connector. The internal address is an IP with an optional port, the
+	// external address is a host with an optional port, ex: 10.0.0.1=203.0.113.1,10.0.0.2:9042=cassandra-2.local:19042
+	AddressTranslationStatic []string `json:"addressTranslation.static"`
+	// Offset added to the port of every node address (after applying the static translations), ex: 10000 maps the port
+	// 9042 to 19042.
+	AddressTranslationPortOffset int `json:"addressTranslation.portOffset" validate:"greater-than=-65536,less-than=65536" default:"0"`
+
+	// How delete records are applied, one of: hard (the row is deleted), soft (the row is marked as deleted by
+	// updating delete.soft.flagColumn and delete.soft.timestampColumn), ttl (the row is re-written with delete.ttl so
+	// it expires).
+	DeleteMode string `json:"deleteMode" validate:"inclusion=hard|soft|ttl" default:"hard"`
+	// Boolean column set to true when a row is soft deleted.
+	DeleteSoftFlagColumn string `json:"delete.soft.flagColumn" default:"deleted"`
+	// Timestamp column set to the time the record was read when a row is soft deleted, it's not set if empty.
+	DeleteSoftTimestampColumn string `json:"delete.soft.timestampColumn" default:"deleted_at"`
+	// Time to live of the rows re-written by the ttl delete mode.
+	DeleteTTL time.Duration `json:"delete.ttl" default:"1h"`
+
+	// How payload fields with a null value are written, one of: null (the column is set to null), unset (the column
+	// is left unchanged, requires the protocol version 4), delete (the column is deleted with a DELETE statement on
+	// updates, and left unchanged on inserts).
+	NullValues string `json:"nullValues" validate:"inclusion=null|unset|delete" default:"null"`
+
+	// Fully qualified name (keyspace.table) of the checkpoint table, used to skip replayed records. The last applied
+	// position is stored for each partition, and records with a position lower than or equal to it are skipped. The
+	// table is created if it doesn't exist. Counter tables and the now(), uuid() and toTimestamp(now()) generators are
+	// unsupported. Replay protection is disabled if empty.
+	CheckpointTable string `json:"checkpoint.table"`
+	// How the positions of the records are ordered, required if checkpoint.table is set, one of: bytes (positions
+	// are compared byte by byte), number (positions are decimal numbers), field (positions are JSON objects ordered
+	// by the number in checkpoint.positionField).
+	CheckpointPositionOrder string `json:"checkpoint.positionOrder" validate:"inclusion=bytes|number|field"`
+	// Field of the JSON positions containing the number the positions are ordered by, nested fields are separated by
+	// dots, ex: offset. Required by the field position order.
+	CheckpointPositionField string `json:"checkpoint.positionField"`
+
+	// How records are written, one of: mirror (records are applied to the table, so it mirrors the source), changelog
+	// (each record is appended as an event to the table, which is created if it doesn't exist).
+	Mode string `json:"mode" validate:"inclusion=mirror|changelog" default:"mirror"`
+	// Format of the records, one of: opencdc (records are written as is), debezium (the payload is a Debezium change
+	// event, its before and after fields are unwrapped, its op field is the operation of the record and its
+	// source.ts_us, source.ts_ns or source.ts_ms field is the write timestamp).
+	InputFormat string `json:"input.format" validate:"inclusion=opencdc|debezium" default:"opencdc"`
+
+	// Name of the key column containing the time bucket of the record, used to split large partitions by time. The
+	// bucket is derived from bucket.field and added to the key of each record. Time buckets are disabled if empty.
+	BucketColumn string `json:"bucket.column"`
+	// Timestamp field the time bucket is derived from, looked up in the key, then in the payload. The field should be
+	// a timestamp in RFC3339 format or milliseconds since epoch. If empty, the opencdc.readAt metadata is used, and
+	// update and delete records are rejected.
+	BucketField string `json:"bucket.field"`
+	// Size of the time buckets, one of: hour, day, month.
+	BucketSize string `json:"bucket.size" validate:"inclusion=hour|day|month" default:"day"`
+	// Go time layout used to format the time bucket, ex: 2006-01-02. Defaults to 2006-01-02T15 for hour buckets,
+	// 2006-01-02 for day buckets and 2006-01 for month buckets. The timestamp is truncated to the bucket size before
+	// it's formatted.
+	BucketFormat string `json:"bucket.format"`
+
+	// Comma separated list of column=generator pairs, used to fill the columns missing from a record, ex:
+	// id=now(),created_at=toTimestamp(now()). Supported generators are now() (timeuuid), uuid(), toTimestamp(now())
+	// and hash(field1|field2) (hex encoded SHA-256 hash of the fields). Only hash can be used with checkpoint.table.
+	Generators []string `json:"generators"`
+
+	// Whether to use the schemas referenced by the opencdc.key.schema.* and opencdc.payload.schema.* metadata of the
+	// records to convert the values into the types of the schema fields.
+	SchemaEnabled bool `json:"schema.enabled" default:"false"`
+	// Whether to validate the table against the record schemas, the key fields should be primary key columns, and
+	// the payload fields should be columns with a compatible type. Requires schema.enabled.
+	SchemaValidate bool `json:"schema.validate" default:"false"`
+	// Whether to create the table from the record schemas if it doesn't exist, the key fields are the partition key
+	// and the payload fields are the other columns. Requires schema.enabled.
+	SchemaAutoCreate bool `json:"schema.autoCreate" default:"false"`
+	// Whether to add a column to the table for each payload field of the record schema that has no column. Requires
+	// schema.enabled.
+	SchemaEvolve bool `json:"schema.evolve" default:"false"`
+
+	// Address the HTTP server exposing the metrics in the Prometheus format listens on, ex: :9464. Metrics are served
+	// at the /metrics path, the server is disabled if empty.
+	MetricsAddress string `json:"metrics.address"`
+
+	// Statements taking longer than this threshold are logged with their coordinator, attempt and consistency level,
+	// slow statements are not logged if zero.
+	SlowQueryThreshold time.Duration `json:"slowQuery.threshold"`
+	// Rate of the statements traced by Cassandra, between 0 (no statement) and 1 (all the statements). The traces are
+	// read from the system_traces keyspace and logged.
+	TracingSampleRate float64 `json:"tracing.sampleRate" default:"0"`
+
+	// Exporter of the OpenTelemetry spans created around writes, batches and statements, one of: none (spans are not
+	// created), otlp (spans are exported to an OTLP collector over gRPC).
+	OTelExporter string `json:"otel.exporter" validate:"inclusion=none|otlp" default:"none"`
+	// Endpoint (host:port) of the OTLP collector, the OTEL_EXPORTER_OTLP_ENDPOINT environment variable or
+	// localhost:4317 is used if empty.
+	OTelEndpoint string `json:"otel.endpoint"`
+	// Whether to disable TLS when connecting to the OTLP collector.
+	OTelInsecure bool `json:"otel.insecure" default:"false"`
+
+	// Maximum number of records written per second, the rate is unlimited if zero.
+	RateLimitRecords float64 `json:"rateLimit.records" default:"0"`
+	// Maximum number of bytes (key and payload) written per second, the rate is unlimited if zero.
+	RateLimitBytes int `json:"rateLimit.bytes" validate:"greater-than=-1" default:"0"`
+	// Whether to halve the records rate when the cluster times out or is overloaded, and to slowly increase it back
+	// to rateLimit.records once the cluster recovers. Only the records rate is adapted, not the concurrency or the
+	// batch size, which are not controlled by the connector. Requires rateLimit.records.
+	RateLimitAdaptive bool `json:"rateLimit.adaptive" default:"false"`
+	// Minimum records rate of the adaptive rate limit.
+	RateLimitAdaptiveMinRecords float64 `json:"rateLimit.adaptive.minRecords" default:"1"`
+
+	// Whether to render the statements of each record instead of executing them, the connector doesn't connect to the
+	// cluster.
+	DryRun bool `json:"dryRun" default:"false"`
+	// File the rendered statements are appended to in dry run, they are logged if empty.
+	DryRunOutput string `json:"dryRun.output"`
+
+	// CQL statement executed for create and snapshot records instead of the generated insert, its named placeholders
+	// are bound to fields of the record, ex: INSERT INTO ks.users (id, name) VALUES (:key.id, :payload.name).
+	QueryInsert string `json:"query.insert"`
+	// CQL statement executed for update records instead of the generated update, with the same placeholders as
+	// query.insert.
+	QueryUpdate string `json:"query.update"`
+	// CQL statement executed for delete records instead of the generated delete, with the same placeholders as
+	// query.insert.
+	QueryDelete string `json:"query.delete"`
+	// Whether the query templates are idempotent, so they are retried when they fail. Templates are not retried by
+	// default, since retrying a statement like a counter increment applies it twice.
+	QueryIdempotent bool `json:"query.idempotent" default:"false"`
+
+	// Denormalized tables each record is also written to, by table name, the tables are in the keyspace of the record.
+	// The record is written to the table and to these tables in a logged batch.
+	FanOut map[string]FanOutTableConfig `json:"fanout"`
+
+	// Avro record schema (JSON) used to decode raw keys that don't reference a schema in their metadata.
+	AvroKeySchema string `json:"avro.keySchema"`
+	// Avro record schema (JSON) used to decode raw payloads that don't reference a schema in their metadata.
+	AvroPayloadSchema string `json:"avro.payloadSchema"`
+}
+
+// FanOutTableConfig configures a denormalized table the records are written to.
+type FanOutTableConfig struct {
+	// Comma separated list of column=field pairs mapping the columns of the table to fields of the record, ex:
+	// email=payload_email,id. Fields are looked up in the key, then in the payload, nested fields are separated by
+	// dots. A column without a field is mapped to the field with the same name. If empty, all the fields of the key
+	// and the payload are written to the columns with the same name.
+	Columns []string `json:"columns"`
+	// Comma separated list of the primary key columns of the table, used to delete rows.
+	Key []string `json:"key"`
 }
 
 const (
 	AuthMechanismBasic = "basic"
 	AuthMechanismNone  = "none"
+	AuthMechanismPlain = "plain"
+	AuthMechanismToken = "token"
 )
 
-var hostRegexRFC1123 = regexp.MustCompile(`^([a-zA-Z0-9]{1}[a-zA-Z0-9-]{0,62}){1}(\.[a-zA-Z0-9]{1}[a-zA-Z0-9-]{0,62})*?$`)
+// nameFn returns the name of a keyspace or a table for a given record.
+type nameFn func(opencdc.Record) (string, error)
+
+var (
+	hostRegexRFC1123 = regexp.MustCompile(`^([a-zA-Z0-9]{1}[a-zA-Z0-9-]{0,62}){1}(\.[a-zA-Z0-9]{1}[a-zA-Z0-9-]{0,62})*?$`)
+	// srvNameRegex matches SRV record names, which are hostnames that can contain underscores, ex: _cql._tcp.cassandra.
+	srvNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]{1}[a-zA-Z0-9_-]{0,62}(\.[a-zA-Z0-9_]{1}[a-zA-Z0-9_-]{0,62})*?$`)
+	// cqlIdentifierRegex matches unquoted and quoted CQL identifiers.
+	cqlIdentifierRegex = regexp.MustCompile(`^([a-zA-Z0-9_]+|"([^"]|"")+")$`)
+)
 
 // validateConfig extra validations needed for destination config.
 func (d *DestinationConfig) validateConfig() error {
-	if d.AuthMechanism == AuthMechanismBasic && (d.AuthUsername == "" || d.AuthPassword == "") {
-		return fmt.Errorf("auth.basic.username and auth.basic.password should be provided for basic authentication mechanism")
+	err := d.validateAuth()
+	if err != nil {
+		return err
+	}
+	err = d.validateNodes()
+	if err != nil {
+		return err
+	}
+	err = d.validateDeadLetterTable()
+	if err != nil {
+		return err
+	}
+	err = d.validateRetry()
+	if err != nil {
+		return err
+	}
+	err = d.validateHostSelection()
+	if err != nil {
+		return err
+	}
+	err = d.validateConnection()
+	if err != nil {
+		return err
+	}
+	err = d.validateAddressTranslation()
+	if err != nil {
+		return err
+	}
+	err = d.validateDeleteMode()
+	if err != nil {
+		return err
+	}
+	err = d.validateNullValues()
+	if err != nil {
+		return err
+	}
+	err = d.validateCheckpointTable()
+	if err != nil {
+		return err
+	}
+	err = d.validateBucket()
+	if err != nil {
+		return err
 	}
-	err := d.validateNodes()
+	err = d.validateSchema()
 	if err != nil {
 		return err
 	}
+	err = d.validateTracing()
+	if err != nil {
+		return err
+	}
+	err = d.validateRateLimit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// parsedConfig contains the values parsed from the destination config.
+type parsedConfig struct {
+	keyspaceFn        nameFn
+	tableFn           nameFn
+	generators        []generator
+	avroKeySchema     *avro.RecordSchema
+	avroPayloadSchema *avro.RecordSchema
+	queryTemplates    map[opencdc.Operation]*queryTemplate
+	fanOut            []fanOutTable
+}
+
+// parseConfig validates the destination config, and parses the values used to write the records.
+func (d *DestinationConfig) parseConfig() (parsedConfig, error) {
+	var p parsedConfig
+	err := d.validateConfig()
+	if err != nil {
+		return p, err
+	}
+	p.keyspaceFn, err = d.keyspaceFunction()
+	if err != nil {
+		return p, err
+	}
+	p.tableFn, err = d.tableFunction()
+	if err != nil {
+		return p, err
+	}
+	p.generators, err = d.parseGenerators()
+	if err != nil {
+		return p, err
+	}
+	p.avroKeySchema, p.avroPayloadSchema, err = d.parseAvroSchemas()
+	if err != nil {
+		return p, err
+	}
+	p.queryTemplates, err = d.parseQueryTemplates()
+	if err != nil {
+		return p, err
+	}
+	p.fanOut, err = d.parseFanOut()
+	if err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func (d *DestinationConfig) validateDeadLetterTable() error {
+	if !d.deadLetterEnabled() {
+		return nil
+	}
+	if d.ErrorsDeadLetterTable == "" {
+		return fmt.Errorf("errors.deadletter.table should be provided when the deadletter error action is used")
+	}
+	keyspace, table, ok := strings.Cut(d.ErrorsDeadLetterTable, ".")
+	if !ok || !cqlIdentifierRegex.MatchString(keyspace) || !cqlIdentifierRegex.MatchString(table) {
+		return fmt.Errorf("invalid errors.deadletter.table %q, should be in the format keyspace.table", d.ErrorsDeadLetterTable)
+	}
+	return nil
+}
+
+func (d *DestinationConfig) validateRetry() error {
+	if d.RetryMinBackoff > d.RetryMaxBackoff {
+		return fmt.Errorf("retry.minBackoff should be less than or equal to retry.maxBackoff")
+	}
+	if _, err := d.newRetryPolicy(); err != nil {
+		return fmt.Errorf("invalid retry.downgradingConsistency: %w", err)
+	}
+	return nil
+}
+
+func (d *DestinationConfig) validateConnection() error {
+	if d.ConnectionConnectTimeout < 0 || d.ConnectionTimeout < 0 || d.ConnectionWriteTimeout < 0 {
+		return fmt.Errorf("connection timeouts should not be negative")
+	}
+	if d.ConnectionReconnectionPolicy == ReconnectionPolicyExponential &&
+		d.ConnectionReconnectionInterval > d.ConnectionReconnectionMaxInterval {
+		return fmt.Errorf("connection.reconnection.interval should be less than or equal to connection.reconnection.maxInterval")
+	}
+	return nil
+}
+
+func (d *DestinationConfig) validateNullValues() error {
+	if d.NullValues == NullValuesUnset && d.ConnectionProtoVersion == 3 {
+		return fmt.Errorf("nullValues %s requires the protocol version 4", NullValuesUnset)
+	}
+	return nil
+}
+
+// keyspaceFunction returns a function that determines the keyspace for each record individually.
+func (d *DestinationConfig) keyspaceFunction() (nameFn, error) {
+	return d.nameFunction("keyspace", d.Keyspace)
+}
+
+// tableFunction returns a function that determines the table for each record individually.
+func (d *DestinationConfig) tableFunction() (nameFn, error) {
+	return d.nameFunction("table", d.Table)
+}
+
+// nameFunction returns a function that returns the static value if it's not a template, otherwise it returns a
+// function that executes the template against each record.
+func (d *DestinationConfig) nameFunction(param, value string) (nameFn, error) {
+	if !isTemplate(value) {
+		return func(opencdc.Record) (string, error) {
+			return value, nil
+		}, nil
+	}
+
+	t, err := template.New(param).Funcs(sprig.FuncMap()).Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid Go template: %w", param, err)
+	}
+
+	return func(r opencdc.Record) (string, error) {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, r); err != nil {
+			return "", fmt.Errorf("failed to execute %s template: %w", param, err)
+		}
+		return buf.String(), nil
+	}, nil
+}
+
+// isTemplate returns true if the value contains a Go template action.
+func isTemplate(value string) bool {
+	return strings.Contains(value, "{{") && strings.Contains(value, "}}")
+}
+
+func (d *DestinationConfig) validateAuth() error {
+	// credentials are only known when the file is read
+	if d.AuthCredentialsFile != "" {
+		return nil
+	}
+	switch d.AuthMechanism {
+	case AuthMechanismBasic, AuthMechanismPlain:
+		if (d.AuthUsername == "" && d.AuthUsernameEnv == "") || (d.AuthPassword == "" && d.AuthPasswordEnv == "") {
+			return fmt.Errorf("auth.basic.username and auth.basic.password should be provided for %s authentication mechanism", d.AuthMechanism)
+		}
+	case AuthMechanismToken:
+		if d.AuthToken == "" && d.AuthTokenEnv == "" {
+			return fmt.Errorf("auth.token should be provided for %s authentication mechanism", d.AuthMechanism)
+		}
+	}
 	return nil
 }
 
 func (d *DestinationConfig) validateNodes() error {
-	var err error
 	for _, n := range d.Nodes {
-		// if it's a host:port format
-		if strings.Contains(n, ":") {
-			err = d.validateHostPort(n)
-		} else {
-			// hostname alone is valid
-			err = d.validateHost(n)
+		var err error
+		switch {
+		case strings.HasPrefix(n, nodeSchemeSRV):
+			if !srvNameRegex.MatchString(strings.TrimPrefix(n, nodeSchemeSRV)) {
+				err = fmt.Errorf("invalid SRV record name")
+			}
+		case strings.HasPrefix(n, nodeSchemeDNS):
+			err = d.validateAddress(strings.TrimPrefix(n, nodeSchemeDNS))
+		default:
+			err = d.validateAddress(n)
 		}
 		if err != nil {
 			return fmt.Errorf("invalid node format %q: %w", n, err)
@@ -75,6 +518,81 @@ func (d *DestinationConfig) validateNodes() error {
 	return nil
 }
 
+func (d *DestinationConfig) validateAddressTranslation() error {
+	for _, entry := range d.AddressTranslationStatic {
+		from, to, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid addressTranslation.static format %q, should be internal=external", entry)
+		}
+		fromHost, _, err := splitOptionalPort(from)
+		if err != nil || net.ParseIP(fromHost) == nil {
+			return fmt.Errorf("invalid addressTranslation.static format %q, internal address should be an IP with an optional port", entry)
+		}
+		if err := d.validateAddress(to); err != nil {
+			return fmt.Errorf("invalid addressTranslation.static format %q: %w", entry, err)
+		}
+		if _, toPort, _ := splitOptionalPort(to); toPort != 0 && !validPort(toPort+d.AddressTranslationPortOffset) {
+			return fmt.Errorf("addressTranslation.portOffset %d makes the port of %q invalid", d.AddressTranslationPortOffset, entry)
+		}
+	}
+	if d.AddressTranslationPortOffset == 0 {
+		return nil
+	}
+	// the ports of SRV records are only known once they are resolved, they are checked when translating
+	for _, n := range d.Nodes {
+		if strings.HasPrefix(n, nodeSchemeSRV) {
+			continue
+		}
+		_, port, err := splitOptionalPort(strings.TrimPrefix(n, nodeSchemeDNS))
+		if err != nil {
+			continue // reported by validateNodes
+		}
+		if port == 0 {
+			port = defaultNodePort
+		}
+		if !validPort(port + d.AddressTranslationPortOffset) {
+			return fmt.Errorf("addressTranslation.portOffset %d makes the port of node %q invalid", d.AddressTranslationPortOffset, n)
+		}
+	}
+	return nil
+}
+
+// validPort returns true if the port is a valid TCP port, 0 excluded.
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+func (d *DestinationConfig) validateAddress(address string) error {
+	// if it's a host:port format
+	if strings.Contains(address, ":") {
+		return d.validateHostPort(address)
+	}
+	// hostname alone is valid
+	return d.validateHost(address)
+}
+
+func (d *DestinationConfig) validateHostSelection() error {
+	switch d.HostSelection {
+	case HostSelectionDCAware:
+		if d.LocalDC == "" {
+			return fmt.Errorf("localDC should be provided for the %s host selection policy", d.HostSelection)
+		}
+	case HostSelectionRackAware:
+		if d.LocalDC == "" || d.LocalRack == "" {
+			return fmt.Errorf("localDC and localRack should be provided for the %s host selection policy", d.HostSelection)
+		}
+	}
+	if d.ShuffleReplicas && d.HostSelection != HostSelectionTokenAware {
+		return fmt.Errorf("shuffleReplicas can only be used with the %s host selection policy", HostSelectionTokenAware)
+	}
+	for _, h := range d.HostAllowList {
+		if err := d.validateAddress(h); err != nil {
+			return fmt.Errorf("invalid hostAllowList format %q: %w", h, err)
+		}
+	}
+	return nil
+}
+
 func (d *DestinationConfig) validateHost(host string) error {
 	if !hostRegexRFC1123.MatchString(host) {
 		return fmt.Errorf("invalid hostname format")
@@ -17,6 +17,7 @@ package cassandra
 import (
 	"testing"
 
+	"github.com/conduitio/conduit-commons/opencdc"
 	"github.com/matryer/is"
 )
 
@@ -159,3 +160,48 @@ func TestConfig_Nodes(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_TableFunction(t *testing.T) {
+	rec := opencdc.Record{
+		Key:      opencdc.StructuredData{"tenant_id": "acme"},
+		Metadata: opencdc.Metadata{"opencdc.collection": "users"},
+	}
+	testCases := []struct {
+		name    string
+		table   string
+		want    string
+		wantErr bool
+	}{{
+		name:  "static table",
+		table: "users",
+		want:  "users",
+	}, {
+		name:  "template from metadata",
+		table: `{{ index .Metadata "opencdc.collection" }}_v2`,
+		want:  "users_v2",
+	}, {
+		name:  "template from key",
+		table: "tenant_{{ .Key.tenant_id }}",
+		want:  "tenant_acme",
+	}, {
+		name:    "invalid template",
+		table:   "{{ if .Key.tenant_id }}",
+		wantErr: true,
+	},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			cfg := DestinationConfig{Table: tt.table}
+			fn, err := cfg.tableFunction()
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			got, err := fn(rec)
+			is.NoErr(err)
+			is.Equal(got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gocql/gocql"
+	"github.com/pierrec/lz4/v4"
+)
+
+const (
+	CompressionNone   = "none"
+	CompressionSnappy = "snappy"
+	CompressionLZ4    = "lz4"
+
+	ReconnectionPolicyConstant    = "constant"
+	ReconnectionPolicyExponential = "exponential"
+)
+
+// applyConnectionConfig applies the connection tuning configurations to the cluster configuration.
+func (d *DestinationConfig) applyConnectionConfig(clusterConfig *gocql.ClusterConfig) {
+	clusterConfig.ConnectTimeout = d.ConnectionConnectTimeout
+	clusterConfig.Timeout = d.ConnectionTimeout
+	clusterConfig.WriteTimeout = d.ConnectionWriteTimeout
+	clusterConfig.NumConns = d.ConnectionNumConns
+	clusterConfig.ProtoVersion = d.ConnectionProtoVersion
+	clusterConfig.DisableInitialHostLookup = d.ConnectionDisableInitialHostLookup
+	clusterConfig.Compressor = d.newCompressor()
+	clusterConfig.ReconnectionPolicy = d.newReconnectionPolicy()
+}
+
+// newCompressor returns the compressor used to compress the frames sent to the cluster, or nil if compression is
+// disabled.
+func (d *DestinationConfig) newCompressor() gocql.Compressor {
+	switch d.ConnectionCompression {
+	case CompressionSnappy:
+		return gocql.SnappyCompressor{}
+	case CompressionLZ4:
+		return lz4Compressor{}
+	default:
+		return nil
+	}
+}
+
+// newReconnectionPolicy returns the policy used to reconnect to hosts that went down.
+func (d *DestinationConfig) newReconnectionPolicy() gocql.ReconnectionPolicy {
+	if d.ConnectionReconnectionPolicy == ReconnectionPolicyExponential {
+		return &gocql.ExponentialReconnectionPolicy{
+			MaxRetries:      d.ConnectionReconnectionMaxRetries,
+			InitialInterval: d.ConnectionReconnectionInterval,
+			MaxInterval:     d.ConnectionReconnectionMaxInterval,
+		}
+	}
+	return &gocql.ConstantReconnectionPolicy{
+		MaxRetries: d.ConnectionReconnectionMaxRetries,
+		Interval:   d.ConnectionReconnectionInterval,
+	}
+}
+
+// lz4Compressor compresses frames using LZ4, in the format expected by Cassandra: the uncompressed length as a 4 byte
+// big endian integer, followed by the compressed block.
+type lz4Compressor struct{}
+
+func (lz4Compressor) Name() string {
+	return CompressionLZ4
+}
+
+func (lz4Compressor) Encode(data []byte) ([]byte, error) {
+	buf := make([]byte, lz4.CompressBlockBound(len(data))+4)
+	var compressor lz4.Compressor
+	n, err := compressor.CompressBlock(data, buf[4:])
+	if err != nil {
+		return nil, err
+	}
+	binary.BigEndian.PutUint32(buf, uint32(len(data))) //nolint:gosec // frames are limited to 256MB
+	return buf[:n+4], nil
+}
+
+func (lz4Compressor) Decode(data []byte) ([]byte, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("lz4 block should be at least 4 bytes long, got %d", len(data))
+	}
+	length := binary.BigEndian.Uint32(data)
+	if length == 0 {
+		return nil, nil
+	}
+	buf := make([]byte, length)
+	n, err := lz4.UncompressBlock(data[4:], buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
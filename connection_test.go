@@ -0,0 +1,75 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+)
+
+func TestLZ4Compressor(t *testing.T) {
+	is := is.New(t)
+	c := lz4Compressor{}
+	data := bytes.Repeat([]byte("conduit cassandra "), 100)
+
+	encoded, err := c.Encode(data)
+	is.NoErr(err)
+	is.True(len(encoded) < len(data))
+
+	decoded, err := c.Decode(encoded)
+	is.NoErr(err)
+	is.Equal(decoded, data)
+
+	_, err = c.Decode([]byte{0, 1})
+	is.True(err != nil)
+}
+
+func TestConfig_Connection(t *testing.T) {
+	is := is.New(t)
+	cfg := DestinationConfig{
+		ConnectionConnectTimeout:           5 * time.Second,
+		ConnectionTimeout:                  3 * time.Second,
+		ConnectionNumConns:                 4,
+		ConnectionProtoVersion:             4,
+		ConnectionCompression:              CompressionSnappy,
+		ConnectionReconnectionPolicy:       ReconnectionPolicyExponential,
+		ConnectionReconnectionMaxRetries:   5,
+		ConnectionReconnectionInterval:     time.Minute,
+		ConnectionReconnectionMaxInterval:  time.Second,
+		ConnectionDisableInitialHostLookup: true,
+	}
+	// interval is greater than the max interval
+	is.True(cfg.validateConfig() != nil)
+	cfg.ConnectionReconnectionMaxInterval = time.Hour
+	is.NoErr(cfg.validateConfig())
+
+	clusterConfig := gocql.NewCluster("localhost")
+	cfg.applyConnectionConfig(clusterConfig)
+	is.Equal(clusterConfig.ConnectTimeout, 5*time.Second)
+	is.Equal(clusterConfig.Timeout, 3*time.Second)
+	is.Equal(clusterConfig.NumConns, 4)
+	is.Equal(clusterConfig.ProtoVersion, 4)
+	is.Equal(clusterConfig.Compressor, gocql.SnappyCompressor{})
+	is.True(clusterConfig.DisableInitialHostLookup)
+	is.Equal(clusterConfig.ReconnectionPolicy, &gocql.ExponentialReconnectionPolicy{
+		MaxRetries:      5,
+		InitialInterval: time.Minute,
+		MaxInterval:     time.Hour,
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+const (
+	// InputFormatOpenCDC writes the records as is.
+	InputFormatOpenCDC = "opencdc"
+	// InputFormatDebezium unwraps the Debezium change event in the payload of the records.
+	InputFormatDebezium = "debezium"
+)
+
+// metadataCassandraTimestamp is the write timestamp of the record in microseconds since epoch.
+const metadataCassandraTimestamp = "cassandra.timestamp"
+
+// debeziumOperations maps the op field of Debezium change events to the OpenCDC operations.
+var debeziumOperations = map[string]opencdc.Operation{
+	"c": opencdc.OperationCreate,
+	"u": opencdc.OperationUpdate,
+	"d": opencdc.OperationDelete,
+	"r": opencdc.OperationSnapshot,
+}
+
+// unwrapDebezium returns the record with the Debezium change event in its payload unwrapped: the before and after
+// fields of the event are the payload, the op field is the operation, and the source timestamp is the write timestamp,
+// check debeziumTimestamp. Events wrapped in the {"schema": ..., "payload": ...} envelope of the Kafka JSON converter
+// are supported for the key and payload. Skip is true for tombstones, the events without a value following deletes.
+func unwrapDebezium(record opencdc.Record) (unwrapped opencdc.Record, skip bool, err error) {
+	if record.Payload.After == nil || len(record.Payload.After.Bytes()) == 0 {
+		return record, true, nil
+	}
+	event, err := debeziumData(record.Payload.After)
+	if err != nil {
+		return record, false, fmt.Errorf("%w: invalid debezium event: %w", errInvalidRecord, err)
+	}
+	if record.Key != nil && len(record.Key.Bytes()) > 0 {
+		key, err := debeziumData(record.Key)
+		if err != nil {
+			return record, false, fmt.Errorf("%w: invalid debezium key: %w", errInvalidRecord, err)
+		}
+		record.Key = opencdc.StructuredData(key)
+	}
+
+	op, _ := event["op"].(string)
+	operation, ok := debeziumOperations[op]
+	if !ok {
+		return record, false, fmt.Errorf("%w: unknown debezium operation %q", errInvalidRecord, op)
+	}
+	record.Operation = operation
+
+	record.Payload = opencdc.Change{}
+	if before, ok := event["before"].(map[string]interface{}); ok {
+		record.Payload.Before = opencdc.StructuredData(before)
+	}
+	if after, ok := event["after"].(map[string]interface{}); ok {
+		record.Payload.After = opencdc.StructuredData(after)
+	}
+
+	if source, ok := event["source"].(map[string]interface{}); ok {
+		if us, ok := debeziumTimestamp(source); ok {
+			// the metadata is copied, so the original record is left unchanged
+			metadata := make(opencdc.Metadata, len(record.Metadata)+1)
+			maps.Copy(metadata, record.Metadata)
+			record.Metadata = metadata
+			record.Metadata[metadataCassandraTimestamp] = strconv.FormatInt(us, 10)
+		}
+	}
+	return record, false, nil
+}
+
+// debeziumTimestamp returns the time of the change in microseconds since epoch, from the most precise timestamp of
+// the source: ts_us, ts_ns (truncated to microseconds), then ts_ms.
+func debeziumTimestamp(source map[string]interface{}) (int64, bool) {
+	if us, ok := toInt64(source["ts_us"]); ok {
+		return us, true
+	}
+	if ns, ok := toInt64(source["ts_ns"]); ok {
+		return ns / 1000, true
+	}
+	if ms, ok := toInt64(source["ts_ms"]); ok {
+		return ms * 1000, true
+	}
+	return 0, false
+}
+
+// debeziumData returns the data as a map, raw data is parsed as JSON. The payload of the schema envelope is returned
+// if the data contains one.
+func debeziumData(data opencdc.Data) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	switch d := data.(type) {
+	case opencdc.StructuredData:
+		m = d
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data.Bytes()))
+		// numbers are kept as json.Number, so they are converted into the column types
+		dec.UseNumber()
+		if err := dec.Decode(&m); err != nil {
+			return nil, err
+		}
+	}
+	if payload, ok := m["payload"].(map[string]interface{}); ok {
+		if _, ok := m["schema"]; ok {
+			return payload, nil
+		}
+	}
+	return m, nil
+}
+
+// writeTimestamp returns the write timestamp of the record in microseconds since epoch, if it has one. Only Debezium
+// events have a write timestamp, since the statements of other records can be lightweight transactions, which can't
+// have a custom timestamp, so the metadata of other records is ignored.
+func (d *DestinationConfig) writeTimestamp(record opencdc.Record) (int64, bool) {
+	if d.InputFormat != InputFormatDebezium {
+		return 0, false
+	}
+	v, ok := record.Metadata[metadataCassandraTimestamp]
+	if !ok {
+		return 0, false
+	}
+	ts, err := strconv.ParseInt(v, 10, 64)
+	return ts, err == nil
+}
@@ -0,0 +1,184 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/matryer/is"
+)
+
+func TestUnwrapDebezium(t *testing.T) {
+	testCases := []struct {
+		name     string
+		record   opencdc.Record
+		want     opencdc.Record
+		wantSkip bool
+		wantErr  bool
+	}{
+		{
+			name: "create",
+			record: opencdc.Record{
+				Operation: opencdc.OperationCreate,
+				Metadata:  opencdc.Metadata{"opencdc.collection": "users"},
+				Key:       opencdc.StructuredData{"id": 1},
+				Payload: opencdc.Change{After: opencdc.StructuredData{
+					"before": nil,
+					"after":  map[string]interface{}{"id": 1, "name": "john"},
+					"op":     "c",
+					"source": map[string]interface{}{"ts_ms": 1704067200000},
+				}},
+			},
+			want: opencdc.Record{
+				Operation: opencdc.OperationCreate,
+				Metadata:  opencdc.Metadata{"opencdc.collection": "users", metadataCassandraTimestamp: "1704067200000000"},
+				Key:       opencdc.StructuredData{"id": 1},
+				Payload:   opencdc.Change{After: opencdc.StructuredData{"id": 1, "name": "john"}},
+			},
+		},
+		{
+			name: "update with the schema envelope",
+			record: opencdc.Record{
+				Operation: opencdc.OperationCreate,
+				Key:       opencdc.RawData(`{"schema": {}, "payload": {"id": 1}}`),
+				Payload: opencdc.Change{After: opencdc.RawData(`{"schema": {}, "payload": {
+					"before": {"id": 1, "name": "john"},
+					"after": {"id": 1, "name": "jane"},
+					"op": "u",
+					"source": {"ts_ms": 1704067200000}
+				}}`)},
+			},
+			want: opencdc.Record{
+				Operation: opencdc.OperationUpdate,
+				Metadata:  opencdc.Metadata{metadataCassandraTimestamp: "1704067200000000"},
+				Key:       opencdc.StructuredData{"id": json.Number("1")},
+				Payload: opencdc.Change{
+					Before: opencdc.StructuredData{"id": json.Number("1"), "name": "john"},
+					After:  opencdc.StructuredData{"id": json.Number("1"), "name": "jane"},
+				},
+			},
+		},
+		{
+			name: "microseconds timestamp",
+			record: opencdc.Record{
+				Key: opencdc.StructuredData{"id": 1},
+				Payload: opencdc.Change{After: opencdc.StructuredData{
+					"after":  map[string]interface{}{"id": 1},
+					"op":     "c",
+					"source": map[string]interface{}{"ts_ms": 1704067200000, "ts_us": 1704067200000123, "ts_ns": 1704067200000123456},
+				}},
+			},
+			want: opencdc.Record{
+				Operation: opencdc.OperationCreate,
+				Metadata:  opencdc.Metadata{metadataCassandraTimestamp: "1704067200000123"},
+				Key:       opencdc.StructuredData{"id": 1},
+				Payload:   opencdc.Change{After: opencdc.StructuredData{"id": 1}},
+			},
+		},
+		{
+			name: "nanoseconds timestamp",
+			record: opencdc.Record{
+				Key: opencdc.StructuredData{"id": 1},
+				Payload: opencdc.Change{After: opencdc.StructuredData{
+					"after":  map[string]interface{}{"id": 1},
+					"op":     "c",
+					"source": map[string]interface{}{"ts_ms": 1704067200000, "ts_ns": 1704067200000123456},
+				}},
+			},
+			want: opencdc.Record{
+				Operation: opencdc.OperationCreate,
+				Metadata:  opencdc.Metadata{metadataCassandraTimestamp: "1704067200000123"},
+				Key:       opencdc.StructuredData{"id": 1},
+				Payload:   opencdc.Change{After: opencdc.StructuredData{"id": 1}},
+			},
+		},
+		{
+			name: "delete",
+			record: opencdc.Record{
+				Operation: opencdc.OperationCreate,
+				Key:       opencdc.StructuredData{"id": 1},
+				Payload: opencdc.Change{After: opencdc.StructuredData{
+					"before": map[string]interface{}{"id": 1},
+					"after":  nil,
+					"op":     "d",
+				}},
+			},
+			want: opencdc.Record{
+				Operation: opencdc.OperationDelete,
+				Key:       opencdc.StructuredData{"id": 1},
+				Payload:   opencdc.Change{Before: opencdc.StructuredData{"id": 1}},
+			},
+		},
+		{
+			name: "tombstone",
+			record: opencdc.Record{
+				Operation: opencdc.OperationCreate,
+				Key:       opencdc.StructuredData{"id": 1},
+			},
+			wantSkip: true,
+		},
+		{
+			name: "unknown operation",
+			record: opencdc.Record{
+				Key:     opencdc.StructuredData{"id": 1},
+				Payload: opencdc.Change{After: opencdc.StructuredData{"op": "t"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid event",
+			record: opencdc.Record{
+				Key:     opencdc.StructuredData{"id": 1},
+				Payload: opencdc.Change{After: opencdc.RawData("not json")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			got, skip, err := unwrapDebezium(tc.record)
+			if tc.wantErr {
+				is.True(errors.Is(err, errInvalidRecord))
+				return
+			}
+			is.NoErr(err)
+			is.Equal(skip, tc.wantSkip)
+			if !tc.wantSkip {
+				is.Equal(got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWriteTimestamp(t *testing.T) {
+	is := is.New(t)
+	cfg := DestinationConfig{InputFormat: InputFormatDebezium}
+	rec := opencdc.Record{Metadata: opencdc.Metadata{metadataCassandraTimestamp: "1704067200000000"}}
+	ts, ok := cfg.writeTimestamp(rec)
+	is.True(ok)
+	is.Equal(ts, int64(1704067200000000))
+
+	_, ok = cfg.writeTimestamp(opencdc.Record{Metadata: opencdc.Metadata{}})
+	is.True(!ok)
+
+	// the metadata is ignored for other formats, since their statements can have conditions
+	cfg.InputFormat = InputFormatOpenCDC
+	_, ok = cfg.writeTimestamp(rec)
+	is.True(!ok)
+}
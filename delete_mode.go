@@ -0,0 +1,109 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/gocql/gocql"
+)
+
+const (
+	// DeleteModeHard physically deletes the rows.
+	DeleteModeHard = "hard"
+	// DeleteModeSoft marks the row as deleted by updating the configured columns.
+	DeleteModeSoft = "soft"
+	// DeleteModeTTL re-writes the row with a TTL, so it expires instead of being deleted.
+	DeleteModeTTL = "ttl"
+)
+
+func (d *DestinationConfig) validateDeleteMode() error {
+	switch d.DeleteMode {
+	case DeleteModeSoft:
+		if !cqlIdentifierRegex.MatchString(d.DeleteSoftFlagColumn) {
+			return fmt.Errorf("invalid delete.soft.flagColumn %q, should be a valid column name", d.DeleteSoftFlagColumn)
+		}
+		if d.DeleteSoftTimestampColumn != "" && !cqlIdentifierRegex.MatchString(d.DeleteSoftTimestampColumn) {
+			return fmt.Errorf("invalid delete.soft.timestampColumn %q, should be a valid column name", d.DeleteSoftTimestampColumn)
+		}
+	case DeleteModeTTL:
+		if d.DeleteTTL < time.Second {
+			return fmt.Errorf("delete.ttl should be at least 1s")
+		}
+	}
+	return nil
+}
+
+// softDelete marks the row as deleted, it sets the flag column to true and the timestamp column to the time the
+// record was read, or the current time if the record doesn't contain it.
+func (d *Destination) softDelete(ctx context.Context, record opencdc.Record, table string) error {
+	columns := opencdc.StructuredData{d.config.DeleteSoftFlagColumn: true}
+	if d.config.DeleteSoftTimestampColumn != "" {
+		deletedAt, err := record.Metadata.GetReadAt()
+		if err != nil {
+			deletedAt = time.Now()
+		}
+		columns[d.config.DeleteSoftTimestampColumn] = deletedAt
+	}
+
+	stmt, err := d.queryBuilder.BuildSoftDeleteQuery(record, table, columns)
+	if err != nil {
+		return fmt.Errorf("error while soft deleting data: %w", err)
+	}
+	applied, err := d.execCAS(ctx, record, table, stmt)
+	if err != nil {
+		return fmt.Errorf("error while soft deleting data: %w", err)
+	}
+	if !applied {
+		return fmt.Errorf("error while soft deleting data: %w, row doesn't exist", errLWTNotApplied)
+	}
+	return nil
+}
+
+// ttlDelete reads the row and writes it back with the configured TTL, so it expires instead of being deleted. Nothing
+// is written if the row doesn't exist. The read and the write are not atomic, an update of the row in between is
+// overwritten with the values that were read.
+func (d *Destination) ttlDelete(ctx context.Context, record opencdc.Record, table string) error {
+	selectStmt, err := d.queryBuilder.BuildSelectJSONQuery(record, table)
+	if err != nil {
+		return fmt.Errorf("error while reading the row to expire: %w", err)
+	}
+	if d.config.DryRun {
+		// the row can't be read in dry run, so only the query reading it is rendered
+		return d.renderDryRun(ctx, record, selectStmt)
+	}
+	var row string
+	err = d.query(ctx, record, table, selectStmt).Idempotent(true).Scan(&row)
+	if errors.Is(err, gocql.ErrNotFound) {
+		sdk.Logger(ctx).Debug().Str("table", table).Msg("row to expire doesn't exist, skipping")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error while reading the row to expire: %w", err)
+	}
+
+	stmt := d.queryBuilder.BuildInsertJSONQuery(record, table, row, d.config.DeleteTTL)
+	// the row is written as is, so the query can be safely retried
+	err = d.exec(ctx, record, table, stmt)
+	if err != nil {
+		return fmt.Errorf("error while expiring data: %w", err)
+	}
+	return nil
+}
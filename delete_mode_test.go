@@ -0,0 +1,104 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/matryer/is"
+)
+
+func TestConfig_DeleteMode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  DestinationConfig
+		wantErr bool
+	}{{
+		name:   "soft",
+		config: DestinationConfig{DeleteMode: DeleteModeSoft, DeleteSoftFlagColumn: "deleted", DeleteSoftTimestampColumn: "deleted_at"},
+	}, {
+		name:   "soft without timestamp",
+		config: DestinationConfig{DeleteMode: DeleteModeSoft, DeleteSoftFlagColumn: "deleted"},
+	}, {
+		name:    "soft with invalid flag column",
+		config:  DestinationConfig{DeleteMode: DeleteModeSoft, DeleteSoftFlagColumn: "is deleted"},
+		wantErr: true,
+	}, {
+		name:   "ttl",
+		config: DestinationConfig{DeleteMode: DeleteModeTTL, DeleteTTL: time.Minute},
+	}, {
+		name:    "ttl less than a second",
+		config:  DestinationConfig{DeleteMode: DeleteModeTTL, DeleteTTL: time.Millisecond},
+		wantErr: true,
+	}}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			err := tt.config.validateConfig()
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+		})
+	}
+}
+
+func TestQueryBuilder_SoftDelete(t *testing.T) {
+	is := is.New(t)
+	builder := QueryBuilder{tables: testTableMetadata}
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rec := opencdc.Record{
+		Operation: opencdc.OperationDelete,
+		Key:       opencdc.StructuredData{"tenant": "acme", "id": 1, "created": deletedAt, "seq": 2},
+	}
+
+	stmt, err := builder.BuildSoftDeleteQuery(rec, "ks.t", opencdc.StructuredData{"deleted": true, "deleted_at": deletedAt})
+	is.NoErr(err)
+	is.Equal(stmt.CQL, "UPDATE ks.t SET deleted = ? , deleted_at = ? WHERE tenant = ? AND id = ? AND created = ? AND seq = ? IF EXISTS")
+	is.Equal(stmt.Values, []interface{}{true, deletedAt, "acme", 1, deletedAt, 2})
+	is.Equal(stmt.Operation, opencdc.OperationDelete)
+
+	// a partial key matches several rows
+	rec.Key = opencdc.StructuredData{"tenant": "acme", "id": 1}
+	_, err = builder.BuildSoftDeleteQuery(rec, "ks.t", opencdc.StructuredData{"deleted": true})
+	is.True(errors.Is(err, errInvalidRecord))
+}
+
+func TestQueryBuilder_TTLDelete(t *testing.T) {
+	is := is.New(t)
+	builder := QueryBuilder{tables: testTableMetadata}
+	rec := opencdc.Record{
+		Operation: opencdc.OperationDelete,
+		Key:       opencdc.StructuredData{"tenant": "acme", "id": 1, "created": 3, "seq": 2},
+	}
+
+	stmt, err := builder.BuildSelectJSONQuery(rec, "ks.t")
+	is.NoErr(err)
+	is.Equal(stmt.CQL, "SELECT JSON * FROM ks.t WHERE tenant = ? AND id = ? AND created = ? AND seq = ?")
+	is.Equal(stmt.Values, []interface{}{"acme", 1, 3, 2})
+
+	stmt = builder.BuildInsertJSONQuery(rec, "ks.t", `{"id": 1}`, 90*time.Minute+500*time.Millisecond)
+	is.Equal(stmt.CQL, "INSERT INTO ks.t JSON ? DEFAULT UNSET USING TTL ?")
+	is.Equal(stmt.Values, []interface{}{`{"id": 1}`, 5400})
+
+	// range deletes can't be applied to a single row
+	rec.Metadata = opencdc.Metadata{metadataCassandraDeleteRange: `{"column": "seq", "gt": 1}`}
+	_, err = builder.BuildSelectJSONQuery(rec, "ks.t")
+	is.True(errors.Is(err, errInvalidRecord))
+}
@@ -17,11 +17,17 @@ package cassandra
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/conduitio/conduit-commons/config"
 	"github.com/conduitio/conduit-commons/opencdc"
 	sdk "github.com/conduitio/conduit-connector-sdk"
 	"github.com/gocql/gocql"
+	"github.com/hamba/avro/v2"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Destination struct {
@@ -30,9 +36,40 @@ type Destination struct {
 	config       DestinationConfig
 	session      *gocql.Session
 	queryBuilder QueryBuilder
+
+	metrics *destinationMetrics
+	limiter *rateLimiter
+	// dryRunOutput is the file the statements are rendered to in dry run, nil if they are logged
+	dryRunOutput *os.File
+	tracer       gocql.Tracer
+	// otelTracer creates the OpenTelemetry spans, and otelShutdown flushes them when the destination is torn down
+	otelTracer   trace.Tracer
+	otelShutdown func(context.Context) error
+
+	keyspaceFn nameFn
+	tableFn    nameFn
+	generators []generator
+	// queryTemplates are the configured statements executed instead of the generated ones, by operation
+	queryTemplates map[opencdc.Operation]*queryTemplate
+	// fanOut are the denormalized tables the records are also written to
+	fanOut []fanOutTable
+
+	// changelogTables are the changelog tables that were created, or already existed
+	changelogTables map[string]bool
+	// schemas are the parsed record schemas, by subject and version
+	schemas map[string]*avro.RecordSchema
+	// avroKeySchema and avroPayloadSchema are the configured schemas used to decode raw data, nil if not configured
+	avroKeySchema     *avro.RecordSchema
+	avroPayloadSchema *avro.RecordSchema
+	// checkedSchemas are the tables and record schemas that were checked, so the table is only created, validated or
+	// evolved once for each version of the schemas
+	checkedSchemas map[string]bool
 }
 
-const metadataCassandraTable = "cassandra.table"
+const (
+	metadataCassandraKeyspace = "cassandra.keyspace"
+	metadataCassandraTable    = "cassandra.table"
+)
 
 func NewDestination() sdk.Destination {
 	return sdk.DestinationWithMiddleware(&Destination{})
@@ -48,23 +85,83 @@ func (d *Destination) Configure(ctx context.Context, cfg config.Config) error {
 	if err != nil {
 		return fmt.Errorf("invalid config: %w", err)
 	}
-	err = d.config.validateConfig()
+	parsed, err := d.config.parseConfig()
 	if err != nil {
 		return fmt.Errorf("invalid config: %w", err)
 	}
+	d.keyspaceFn = parsed.keyspaceFn
+	d.tableFn = parsed.tableFn
+	d.generators = parsed.generators
+	d.avroKeySchema, d.avroPayloadSchema = parsed.avroKeySchema, parsed.avroPayloadSchema
+	d.queryTemplates = parsed.queryTemplates
+	d.fanOut = parsed.fanOut
+	d.limiter = d.config.newRateLimiter()
 	return nil
 }
 
 func (d *Destination) Open(ctx context.Context) error {
 	sdk.Logger(ctx).Info().Msg("Opening the connector.")
+	if d.config.DryRun {
+		return d.openDryRun(ctx)
+	}
 	// Define the Cassandra cluster configuration
-	clusterConfig := gocql.NewCluster(d.config.Nodes...)
-	clusterConfig.Keyspace = d.config.Keyspace
+	nodes, err := d.config.resolveNodes(ctx)
+	if err != nil {
+		return err
+	}
+	clusterConfig := gocql.NewCluster(nodes...)
+	// a templated keyspace is resolved per record, queries use fully qualified table names
+	if !isTemplate(d.config.Keyspace) {
+		clusterConfig.Keyspace = d.config.Keyspace
+	}
+
+	addressTranslator, err := d.config.newAddressTranslator(ctx)
+	if err != nil {
+		return err
+	}
+	clusterConfig.AddressTranslator = addressTranslator
+
+	retryPolicy, err := d.config.newRetryPolicy()
+	if err != nil {
+		return fmt.Errorf("invalid retry policy: %w", err)
+	}
+	clusterConfig.RetryPolicy = retryPolicy
+
+	d.config.applyConnectionConfig(clusterConfig)
+
+	clusterConfig.PoolConfig.HostSelectionPolicy = d.config.newHostSelectionPolicy()
+	hostFilter, err := d.config.newHostFilter(ctx)
+	if err != nil {
+		return err
+	}
+	clusterConfig.HostFilter = hostFilter
+
+	clusterConfig.AuthProvider = d.config.newAuthProvider()
+
+	tp, shutdown, err := d.config.newTracerProvider(ctx)
+	if err != nil {
+		return err
+	}
+	d.otelTracer = tp.Tracer(instrumentationName)
+	d.otelShutdown = shutdown
 
-	if d.config.AuthMechanism == AuthMechanismBasic {
-		clusterConfig.Authenticator = gocql.PasswordAuthenticator{
-			Username: d.config.AuthUsername,
-			Password: d.config.AuthPassword,
+	d.metrics = newDestinationMetrics()
+	obs := observers{d.metrics}
+	if d.limiter != nil && d.limiter.adaptive != nil {
+		obs = append(obs, d.limiter)
+	}
+	if d.config.OTelExporter == OTelExporterOTLP {
+		obs = append(obs, &spanObserver{tracer: d.otelTracer})
+	}
+	if d.config.SlowQueryThreshold > 0 {
+		obs = append(obs, &slowQueryLogger{threshold: d.config.SlowQueryThreshold, logger: sdk.Logger(ctx)})
+	}
+	clusterConfig.QueryObserver = obs
+	clusterConfig.BatchObserver = obs
+	if d.config.MetricsAddress != "" {
+		err = d.metrics.serve(ctx, d.config.MetricsAddress)
+		if err != nil {
+			return err
 		}
 	}
 
@@ -74,66 +171,302 @@ func (d *Destination) Open(ctx context.Context) error {
 		return fmt.Errorf("error connecting to the cassandra cluster: %w", err)
 	}
 	d.session = session
+	if d.config.TracingSampleRate > 0 {
+		d.tracer = gocql.NewTraceWriter(session, traceLogWriter{logger: sdk.Logger(ctx)})
+	}
+	d.queryBuilder = QueryBuilder{
+		tables:     d.tableMetadata,
+		nullValues: d.config.NullValues,
+		// writes are batched with the checkpoint update or the fan-out tables, which isn't possible with lightweight
+		// transactions, and writes with a timestamp are ordered by their timestamp instead of conditions
+		unconditional: d.config.checkpointEnabled() || d.config.fanOutEnabled() ||
+			d.config.InputFormat == InputFormatDebezium,
+	}
+
+	err = d.prepareQueryTemplates()
+	if err != nil {
+		return err
+	}
+	if d.config.deadLetterEnabled() {
+		err = d.createDeadLetterTable()
+		if err != nil {
+			return err
+		}
+	}
+	if d.config.checkpointEnabled() {
+		err = d.createCheckpointTable()
+		if err != nil {
+			return err
+		}
+	}
+	d.changelogTables = make(map[string]bool)
 	return nil
 }
 
-func (d *Destination) Write(ctx context.Context, records []opencdc.Record) (int, error) {
+func (d *Destination) Write(ctx context.Context, records []opencdc.Record) (n int, err error) {
+	ctx, span := d.otel().Start(ctx, "cassandra.Write", trace.WithAttributes(attribute.Int("records", len(records))))
+	defer func() {
+		span.SetAttributes(attribute.Int("records.written", n))
+		endSpan(span, err)
+	}()
+
+	d.metrics.observeBatch(records)
 	for i, r := range records {
-		err := d.validateStructuredRecord(r)
+		err = d.limiter.wait(ctx, r)
+		if err != nil {
+			return i, err
+		}
+		err = d.writeRecord(ctx, r)
+		if err != nil {
+			err = d.handleWriteError(ctx, r, err)
+			if err != nil {
+				return i, err
+			}
+		}
+	}
+	sdk.Logger(ctx).Trace().Msgf("%v records written to destination", len(records))
+	return len(records), nil
+}
+
+// writeRecord validates the record and routes it to the handler of its operation, or appends it to the changelog in
+// changelog mode.
+func (d *Destination) writeRecord(ctx context.Context, record opencdc.Record) error {
+	var err error
+	// the changelog accepts raw data, it's stored as is
+	if d.config.Mode != ModeChangelog {
+		record, err = d.decodeAvro(ctx, record)
 		if err != nil {
-			return i, fmt.Errorf("invalid record format: %w", err)
+			return err
 		}
+		if d.config.InputFormat == InputFormatDebezium {
+			var skip bool
+			record, skip, err = unwrapDebezium(record)
+			if err != nil {
+				return err
+			}
+			if skip {
+				sdk.Logger(ctx).Debug().Msg("skipping debezium tombstone")
+				return nil
+			}
+		}
+		err = d.validateStructuredRecord(record)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errInvalidRecord, err)
+		}
+	}
+
+	if d.config.bucketEnabled() {
+		record, err = d.addBucket(record)
+		if err != nil {
+			return err
+		}
+	}
 
-		err = sdk.Util.Destination.Route(ctx, r,
+	table, err := d.getTableName(record)
+	if err != nil {
+		return err
+	}
+
+	if d.config.SchemaEnabled && d.config.Mode != ModeChangelog {
+		record, err = d.applyRecordSchema(ctx, record, table)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(d.generators) > 0 && d.config.Mode != ModeChangelog {
+		record, err = d.generateValues(record, table)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the checkpoint can't be read in dry run
+	if d.config.checkpointEnabled() && !d.config.DryRun {
+		replayed, err := d.isReplayed(ctx, record, table)
+		if err != nil {
+			return err
+		}
+		if replayed {
+			sdk.Logger(ctx).Debug().Str("table", table).Msg("record was already applied, skipping")
+			return nil
+		}
+	}
+
+	ctx, span := d.otel().Start(ctx, "cassandra.writeRecord", trace.WithAttributes(
+		semconv.DBCollectionName(table),
+		semconv.DBOperationName(record.Operation.String()),
+	))
+	start := time.Now()
+	qt, hasTemplate := d.queryTemplates[record.Operation]
+	switch {
+	case hasTemplate:
+		err = d.execTemplate(ctx, record, table, qt)
+	case d.config.Mode == ModeChangelog:
+		err = d.writeChangelog(ctx, record)
+	case d.config.fanOutEnabled():
+		err = d.writeFanOut(ctx, record, table)
+	default:
+		err = sdk.Util.Destination.Route(ctx, record,
 			d.handleInsert, // create
 			d.handleUpdate, // update
 			d.handleDelete, // delete
 			d.handleInsert, // snapshot
 		)
-		if err != nil {
-			return i, err
-		}
 	}
-	sdk.Logger(ctx).Trace().Msgf("%v records written to destination", len(records))
-	return len(records), nil
+	d.metrics.observeRecord(record.Operation, table, time.Since(start), err)
+	endSpan(span, err)
+	return err
 }
 
-func (d *Destination) Teardown(context.Context) error {
+func (d *Destination) Teardown(ctx context.Context) error {
 	if d.session != nil {
 		d.session.Close()
 	}
-	return nil
+	if d.dryRunOutput != nil {
+		if err := d.dryRunOutput.Close(); err != nil {
+			return fmt.Errorf("error while closing the dry run output: %w", err)
+		}
+	}
+	if d.otelShutdown != nil {
+		if err := d.otelShutdown(ctx); err != nil {
+			return fmt.Errorf("error while flushing the spans: %w", err)
+		}
+	}
+	return d.metrics.close(ctx)
 }
 
 // handleInsert create and execute the cql query to insert a row.
-func (d *Destination) handleInsert(_ context.Context, record opencdc.Record) error {
-	table := d.getTableName(record.Metadata)
-	query, vals := d.queryBuilder.BuildInsertQuery(record, table)
-	err := d.session.Query(query, vals...).Exec()
+func (d *Destination) handleInsert(ctx context.Context, record opencdc.Record) error {
+	table, err := d.getTableName(record)
+	if err != nil {
+		return err
+	}
+	applied, err := d.execCAS(ctx, record, table, d.queryBuilder.BuildInsertQuery(record, table))
 	if err != nil {
 		return fmt.Errorf("error while inserting data: %w", err)
 	}
+	if !applied {
+		return fmt.Errorf("error while inserting data: %w, row already exists", errLWTNotApplied)
+	}
 
 	return nil
 }
 
 // handleUpdate create and execute the cql query to update a row.
-func (d *Destination) handleUpdate(_ context.Context, record opencdc.Record) error {
-	table := d.getTableName(record.Metadata)
-	query, vals := d.queryBuilder.BuildUpdateQuery(record, table)
-	err := d.session.Query(query, vals...).Exec()
+func (d *Destination) handleUpdate(ctx context.Context, record opencdc.Record) error {
+	table, err := d.getTableName(record)
+	if err != nil {
+		return err
+	}
+	applied, err := d.execCAS(ctx, record, table, d.queryBuilder.BuildUpdateStatements(record, table)...)
 	if err != nil {
 		return fmt.Errorf("error while updating data: %w", err)
 	}
+	if !applied {
+		return fmt.Errorf("error while updating data: %w, row doesn't exist", errLWTNotApplied)
+	}
 
 	return nil
 }
 
-// handleDelete create and execute the cql query to delete a row.
-func (d *Destination) handleDelete(_ context.Context, record opencdc.Record) error {
-	table := d.getTableName(record.Metadata)
-	query, vals := d.queryBuilder.BuildDeleteQuery(record, table)
-	err := d.session.Query(query, vals...).Exec()
+// execCAS executes the conditional statements and returns whether they were applied. Multiple statements are
+// executed in a logged batch, so they are applied only if all the conditions are met. If the query builder is
+// unconditional, ex: with replay protection, fan-out tables or the debezium input format, the statements are upserts
+// executed with exec, and they are always applied.
+func (d *Destination) execCAS(ctx context.Context, record opencdc.Record, table string, stmts ...Statement) (bool, error) {
+	if d.queryBuilder.unconditional {
+		// unconditional statements return no [applied] row, which gocql reports as not found
+		return true, d.exec(ctx, record, table, stmts...)
+	}
+	if d.config.DryRun {
+		return true, d.renderDryRun(ctx, record, stmts...)
+	}
+	if len(stmts) == 1 {
+		return d.query(ctx, record, table, stmts[0]).MapScanCAS(map[string]interface{}{})
+	}
+	batch := d.batch(ctx, record, table)
+	for _, stmt := range stmts {
+		batch.Query(stmt.CQL, stmt.Values...)
+	}
+	applied, iter, err := d.session.MapExecuteBatchCAS(batch, map[string]interface{}{})
+	if err != nil {
+		return false, err
+	}
+	return applied, iter.Close()
+}
+
+// exec executes the statements, they are retried unless one of them is non-idempotent. Multiple statements are
+// executed in a logged batch.
+func (d *Destination) exec(ctx context.Context, record opencdc.Record, table string, stmts ...Statement) error {
+	if d.config.checkpointEnabled() {
+		return d.execWithCheckpoint(ctx, record, table, stmts)
+	}
+	if d.config.DryRun {
+		return d.renderDryRun(ctx, record, stmts...)
+	}
+	if len(stmts) == 1 {
+		return d.query(ctx, record, table, stmts[0]).Idempotent(!stmts[0].NonIdempotent).Exec()
+	}
+	batch := d.batch(ctx, record, table)
+	for _, stmt := range stmts {
+		batch.Entries = append(batch.Entries, gocql.BatchEntry{Stmt: stmt.CQL, Args: stmt.Values, Idempotent: !stmt.NonIdempotent})
+	}
+	return d.session.ExecuteBatch(batch)
+}
+
+// query returns the query of the statement, using the write timestamp of the record if it has one. The query is
+// sampled for tracing, and its context carries the statement information for the observers.
+func (d *Destination) query(ctx context.Context, record opencdc.Record, table string, stmt Statement) *gocql.Query {
+	info := &statementInfo{table: table, operation: record.Operation}
+	q := d.session.Query(stmt.CQL, stmt.Values...).WithContext(contextWithStatementInfo(ctx, info))
+	info.consistency = q.GetConsistency
+	if ts, ok := d.config.writeTimestamp(record); ok {
+		q = q.WithTimestamp(ts)
+	}
+	if d.sampleTrace() {
+		q = q.Trace(d.tracer)
+	}
+	return q
+}
+
+// batch returns a logged batch, using the write timestamp of the record if it has one. The batch is sampled for
+// tracing, and its context carries the statement information for the observers.
+func (d *Destination) batch(ctx context.Context, record opencdc.Record, table string) *gocql.Batch {
+	info := &statementInfo{table: table, operation: record.Operation}
+	b := d.session.NewBatch(gocql.LoggedBatch).WithContext(contextWithStatementInfo(ctx, info))
+	info.consistency = b.GetConsistency
+	if ts, ok := d.config.writeTimestamp(record); ok {
+		b.WithTimestamp(ts)
+	}
+	if d.sampleTrace() {
+		b.Trace(d.tracer)
+	}
+	return b
+}
+
+// handleDelete create and execute the cql query to delete a row, or to mark it as deleted depending on the delete
+// mode. Column deletes (cassandra.delete.columns metadata) are always applied as is.
+func (d *Destination) handleDelete(ctx context.Context, record opencdc.Record) error {
+	table, err := d.getTableName(record)
+	if err != nil {
+		return err
+	}
+	if _, ok := record.Metadata[metadataCassandraDeleteColumns]; !ok {
+		switch d.config.DeleteMode {
+		case DeleteModeSoft:
+			return d.softDelete(ctx, record, table)
+		case DeleteModeTTL:
+			return d.ttlDelete(ctx, record, table)
+		}
+	}
+
+	stmt, err := d.queryBuilder.BuildDeleteQuery(record, table)
+	if err != nil {
+		return fmt.Errorf("error while deleting data: %w", err)
+	}
+	// deleting a row is idempotent, so the query can be safely retried
+	err = d.exec(ctx, record, table, stmt)
 	if err != nil {
 		return fmt.Errorf("error while deleting data: %w", err)
 	}
@@ -157,12 +490,30 @@ func (d *Destination) validateStructuredRecord(record opencdc.Record) error {
 	return nil
 }
 
-// getTableName returns the table name from the record metadata, or if that doesn't exist, then it returns the table
-// name from the connector configurations.
-func (d *Destination) getTableName(metadata map[string]string) string {
-	tableName, ok := metadata[metadataCassandraTable]
+// getTableName returns the fully qualified table name (keyspace.table) for the record. The keyspace and table are
+// taken from the record metadata if they exist, otherwise they are determined using the connector configurations.
+func (d *Destination) getTableName(record opencdc.Record) (string, error) {
+	var err error
+	keyspace, ok := record.Metadata[metadataCassandraKeyspace]
 	if !ok {
-		return d.config.Table
+		keyspace, err = d.keyspaceFn(record)
+		if err != nil {
+			return "", err
+		}
+	}
+	table, ok := record.Metadata[metadataCassandraTable]
+	if !ok {
+		table, err = d.tableFn(record)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if !cqlIdentifierRegex.MatchString(keyspace) {
+		return "", fmt.Errorf("invalid keyspace name %q", keyspace)
+	}
+	if !cqlIdentifierRegex.MatchString(table) {
+		return "", fmt.Errorf("invalid table name %q", table)
 	}
-	return tableName
+	return keyspace + "." + table, nil
 }
@@ -214,6 +214,71 @@ func TestDestination_Data_Format(t *testing.T) {
 	}
 }
 
+func TestDestination_WriteDebezium(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	session := simpleConnect(t)
+	table := setupTest(t, session)
+
+	// inserts and updates are unconditional in this format, so they return no [applied] row
+	destination := NewDestination()
+	err := destination.Configure(ctx, map[string]string{
+		"nodes":        testNodes,
+		"keyspace":     testKeyspace,
+		"table":        table,
+		"input.format": InputFormatDebezium,
+	})
+	is.NoErr(err)
+	err = destination.Open(ctx)
+	is.NoErr(err)
+	defer func() {
+		err := destination.Teardown(ctx)
+		is.NoErr(err)
+	}()
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	testCases := []struct {
+		name  string
+		op    string
+		key   opencdc.StructuredData
+		after map[string]interface{}
+	}{{
+		name:  "create event",
+		op:    "c",
+		key:   opencdc.StructuredData{"id1": "6", "id2": 6},
+		after: map[string]interface{}{"column1": 22, "column2": true, "column3": now},
+	}, {
+		name: "update event",
+		op:   "u",
+		// this row is already in the table
+		key:   opencdc.StructuredData{"id1": "1", "id2": 1},
+		after: map[string]interface{}{"column1": 44, "column2": false, "column3": now},
+	}}
+	for i, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is = is.New(t)
+			record := opencdc.Record{
+				Position:  opencdc.Position(fmt.Sprint(i)),
+				Operation: opencdc.OperationCreate,
+				Key:       tt.key,
+				Payload: opencdc.Change{After: opencdc.StructuredData{
+					"op":     tt.op,
+					"after":  tt.after,
+					"source": map[string]interface{}{"ts_us": now.UnixMicro() + int64(i)},
+				}},
+			}
+			n, err := destination.Write(ctx, []opencdc.Record{record})
+			is.NoErr(err)
+			is.Equal(n, 1)
+
+			got, err := queryTestTable(session, table, tt.key["id1"], tt.key["id2"])
+			is.NoErr(err)
+			is.Equal(opencdc.StructuredData(tt.after), got)
+		})
+	}
+}
+
 func simpleConnect(t *testing.T) *gocql.Session {
 	t.Helper()
 
@@ -0,0 +1,74 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/matryer/is"
+)
+
+func TestDestination_GetTableName(t *testing.T) {
+	ctx := context.Background()
+	is := is.New(t)
+	d := &Destination{}
+	err := d.Configure(ctx, map[string]string{
+		"nodes":    "localhost:9042",
+		"keyspace": "tenant_{{ .Key.tenant_id }}",
+		"table":    `{{ index .Metadata "opencdc.collection" }}_v2`,
+	})
+	is.NoErr(err)
+
+	testCases := []struct {
+		name     string
+		metadata opencdc.Metadata
+		want     string
+		wantErr  bool
+	}{{
+		name:     "templated keyspace and table",
+		metadata: opencdc.Metadata{"opencdc.collection": "users"},
+		want:     "tenant_acme.users_v2",
+	}, {
+		name: "metadata overrides",
+		metadata: opencdc.Metadata{
+			"opencdc.collection":      "users",
+			metadataCassandraKeyspace: "other",
+			metadataCassandraTable:    "people",
+		},
+		want: "other.people",
+	}, {
+		name:     "invalid table name",
+		metadata: opencdc.Metadata{metadataCassandraTable: "users; DROP TABLE users"},
+		wantErr:  true,
+	},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			got, err := d.getTableName(opencdc.Record{
+				Key:      opencdc.StructuredData{"tenant_id": "acme"},
+				Metadata: tt.metadata,
+			})
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(got, tt.want)
+		})
+	}
+}
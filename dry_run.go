@@ -0,0 +1,167 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/gocql/gocql"
+	"gopkg.in/inf.v0"
+)
+
+// openDryRun opens the destination without connecting to the cluster, statements are rendered instead of executed.
+// Table schemas are unknown, so the payload columns are sorted lexicographically, followed by the key columns.
+func (d *Destination) openDryRun(ctx context.Context) error {
+	sdk.Logger(ctx).Warn().Msg("dry run, the statements are rendered instead of being executed")
+	if d.config.DryRunOutput != "" {
+		f, err := os.OpenFile(d.config.DryRunOutput, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("error while opening the dry run output: %w", err)
+		}
+		d.dryRunOutput = f
+	}
+	d.queryBuilder = QueryBuilder{
+		nullValues: d.config.NullValues,
+		unconditional: d.config.checkpointEnabled() || d.config.fanOutEnabled() ||
+			d.config.InputFormat == InputFormatDebezium,
+	}
+	d.changelogTables = make(map[string]bool)
+	return nil
+}
+
+// renderDryRun renders the statements executed for the record, to the dry run output or the log. Multiple
+// statements, or statements with a write timestamp, are rendered as a logged batch.
+func (d *Destination) renderDryRun(ctx context.Context, record opencdc.Record, stmts ...Statement) error {
+	var sb strings.Builder
+	ts, hasTimestamp := d.config.writeTimestamp(record)
+	if len(stmts) == 1 && !hasTimestamp {
+		sb.WriteString(renderStatement(stmts[0]))
+		sb.WriteString(";")
+	} else {
+		sb.WriteString("BEGIN BATCH ")
+		if hasTimestamp {
+			sb.WriteString("USING TIMESTAMP " + strconv.FormatInt(ts, 10) + " ")
+		}
+		for _, stmt := range stmts {
+			sb.WriteString(renderStatement(stmt))
+			sb.WriteString("; ")
+		}
+		sb.WriteString("APPLY BATCH;")
+	}
+
+	if d.dryRunOutput == nil {
+		sdk.Logger(ctx).Info().
+			Str("operation", record.Operation.String()).
+			Str("position", string(record.Position)).
+			Str("statement", sb.String()).
+			Msg("dry run")
+		return nil
+	}
+	_, err := fmt.Fprintln(d.dryRunOutput, sb.String())
+	if err != nil {
+		return fmt.Errorf("error while writing to the dry run output: %w", err)
+	}
+	return nil
+}
+
+// renderStatement returns the CQL of the statement, with the placeholders replaced by the bound values. Question
+// marks in string literals are not placeholders, they are left as is.
+func renderStatement(stmt Statement) string {
+	var sb strings.Builder
+	values := stmt.Values
+	quoted := false
+	for _, r := range stmt.CQL {
+		if r == '\'' {
+			// an escaped quote ('') toggles twice, so it stays in the literal
+			quoted = !quoted
+		}
+		if r == '?' && !quoted && len(values) > 0 {
+			sb.WriteString(cqlLiteral(values[0]))
+			values = values[1:]
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// cqlLiteral returns the value as a CQL literal. Map keys are sorted, so a value is always rendered the same way.
+func cqlLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case []byte:
+		return "0x" + hex.EncodeToString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return "'" + v.UTC().Format("2006-01-02T15:04:05.000Z") + "'"
+	case time.Duration:
+		return strconv.FormatInt(v.Nanoseconds(), 10)
+	case gocql.UUID:
+		return v.String()
+	case *inf.Dec:
+		return v.String()
+	case cqlFunction:
+		return string(v)
+	}
+	if value == gocql.UnsetValue {
+		// unset values leave the column unchanged, they have no CQL literal
+		return "UNSET"
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Slice, reflect.Array:
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = cqlLiteral(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Map:
+		entries := make([]string, 0, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			entries = append(entries, cqlLiteral(iter.Key().Interface())+": "+cqlLiteral(iter.Value().Interface()))
+		}
+		slices.Sort(entries)
+		return "{" + strings.Join(entries, ", ") + "}"
+	default:
+		return cqlLiteral(fmt.Sprint(value))
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+	"gopkg.in/inf.v0"
+)
+
+func TestCQLLiteral(t *testing.T) {
+	uuid, _ := gocql.ParseUUID("f47ac10b-58cc-4372-a567-0e02b2c3d479")
+	testCases := []struct {
+		value interface{}
+		want  string
+	}{
+		{value: nil, want: "NULL"},
+		{value: "it's", want: "'it''s'"},
+		{value: []byte{0xca, 0xfe}, want: "0xcafe"},
+		{value: true, want: "true"},
+		{value: json.Number("12"), want: "12"},
+		{value: float64(1.5), want: "1.5"},
+		{value: int32(-3), want: "-3"},
+		{value: uint8(3), want: "3"},
+		{value: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), want: "'2024-01-01T10:00:00.000Z'"},
+		{value: uuid, want: "f47ac10b-58cc-4372-a567-0e02b2c3d479"},
+		{value: inf.NewDec(1250, 2), want: "12.50"},
+		{value: cqlFunction("now()"), want: "now()"},
+		{value: gocql.UnsetValue, want: "UNSET"},
+		{value: []interface{}{"a", 1}, want: "['a', 1]"},
+		{value: map[string]interface{}{"b": 2, "a": "x"}, want: "{'a': 'x', 'b': 2}"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(cqlLiteral(tc.value), tc.want)
+		})
+	}
+}
+
+func TestRenderStatement(t *testing.T) {
+	is := is.New(t)
+	got := renderStatement(Statement{
+		CQL:    "INSERT INTO ks.users (age, id, name) VALUES (?, ?, ?) IF NOT EXISTS",
+		Values: []interface{}{json.Number("22"), json.Number("1"), "john"},
+	})
+	is.Equal(got, "INSERT INTO ks.users (age, id, name) VALUES (22, 1, 'john') IF NOT EXISTS")
+
+	// question marks in string literals are not placeholders
+	got = renderStatement(Statement{
+		CQL:    "UPDATE ks.users SET status = 'why?', note = 'it''s ?' WHERE id = ?",
+		Values: []interface{}{json.Number("1")},
+	})
+	is.Equal(got, "UPDATE ks.users SET status = 'why?', note = 'it''s ?' WHERE id = 1")
+}
+
+func TestDestination_DryRun(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	output := filepath.Join(t.TempDir(), "dry_run.cql")
+
+	d := &Destination{}
+	err := d.Configure(ctx, map[string]string{
+		"nodes":         "localhost:9042",
+		"keyspace":      "ks",
+		"table":         "users",
+		"dryRun":        "true",
+		"dryRun.output": output,
+	})
+	is.NoErr(err)
+	// the destination doesn't connect to the cluster
+	is.NoErr(d.Open(ctx))
+
+	n, err := d.Write(ctx, []opencdc.Record{{
+		Operation: opencdc.OperationCreate,
+		Key:       opencdc.StructuredData{"id": json.Number("1")},
+		Payload:   opencdc.Change{After: opencdc.StructuredData{"name": "john", "age": json.Number("22")}},
+	}, {
+		Operation: opencdc.OperationUpdate,
+		Key:       opencdc.StructuredData{"id": json.Number("1")},
+		Payload:   opencdc.Change{After: opencdc.StructuredData{"name": "jane"}},
+	}, {
+		Operation: opencdc.OperationDelete,
+		Key:       opencdc.StructuredData{"id": json.Number("1")},
+	}})
+	is.NoErr(err)
+	is.Equal(n, 3)
+	is.NoErr(d.Teardown(ctx))
+
+	got, err := os.ReadFile(output)
+	is.NoErr(err)
+	is.Equal(string(got), "INSERT INTO ks.users (age, name, id) VALUES (22, 'john', 1) IF NOT EXISTS;\n"+
+		"UPDATE ks.users SET name = 'jane' WHERE id = 1 IF EXISTS;\n"+
+		"DELETE FROM ks.users WHERE id = 1;\n")
+}
+
+func TestDestination_RenderDryRunBatch(t *testing.T) {
+	is := is.New(t)
+	output, err := os.Create(filepath.Join(t.TempDir(), "dry_run.cql"))
+	is.NoErr(err)
+	d := &Destination{config: DestinationConfig{InputFormat: InputFormatDebezium}, dryRunOutput: output}
+
+	err = d.renderDryRun(context.Background(),
+		opencdc.Record{Metadata: opencdc.Metadata{metadataCassandraTimestamp: "1704067200000000"}},
+		Statement{CQL: "UPDATE ks.users SET name = ? WHERE id = ?", Values: []interface{}{"jane", 1}},
+		Statement{CQL: "UPDATE ks.checkpoints SET position = ? WHERE id = ?", Values: []interface{}{[]byte("p1"), "ks.users"}},
+	)
+	is.NoErr(err)
+	is.NoErr(output.Close())
+
+	got, err := os.ReadFile(output.Name())
+	is.NoErr(err)
+	is.Equal(string(got), "BEGIN BATCH USING TIMESTAMP 1704067200000000 UPDATE ks.users SET name = 'jane' WHERE id = 1; "+
+		"UPDATE ks.checkpoints SET position = 0x7031 WHERE id = 'ks.users'; APPLY BATCH;\n")
+}
@@ -0,0 +1,178 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/gocql/gocql"
+)
+
+const (
+	ErrorActionFail       = "fail"
+	ErrorActionSkip       = "skip"
+	ErrorActionDeadLetter = "deadletter"
+)
+
+// errorClass is the class of an error that occurred while writing a record, each class has its own configured action.
+type errorClass string
+
+const (
+	errorClassMarshal         errorClass = "marshal"
+	errorClassUndefinedColumn errorClass = "undefinedColumn"
+	errorClassLWT             errorClass = "lwt"
+	errorClassTimeout         errorClass = "timeout"
+	errorClassOther           errorClass = "other"
+)
+
+const (
+	createDeadLetterTableQuery = `CREATE TABLE IF NOT EXISTS %s (
+		id timeuuid PRIMARY KEY,
+		created_at timestamp,
+		target_table text,
+		operation text,
+		position blob,
+		record text,
+		error_class text,
+		error text
+	)`
+	insertDeadLetterQuery = "INSERT INTO %s (id, created_at, target_table, operation, position, record, error_class, error) " +
+		"VALUES (now(), toTimestamp(now()), ?, ?, ?, ?, ?, ?)"
+)
+
+var (
+	errInvalidRecord = errors.New("invalid record format")
+	errLWTNotApplied = errors.New("lightweight transaction was not applied")
+)
+
+// classifyError returns the class of the error returned while writing a record.
+func classifyError(err error) errorClass {
+	var marshalErr gocql.MarshalError
+	var writeTimeoutErr *gocql.RequestErrWriteTimeout
+	var readTimeoutErr *gocql.RequestErrReadTimeout
+	var reqErr gocql.RequestError
+	switch {
+	case errors.Is(err, errInvalidRecord), errors.As(err, &marshalErr):
+		return errorClassMarshal
+	case errors.Is(err, errLWTNotApplied):
+		return errorClassLWT
+	case errors.Is(err, gocql.ErrTimeoutNoResponse), errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &writeTimeoutErr), errors.As(err, &readTimeoutErr):
+		return errorClassTimeout
+	case errors.As(err, &reqErr):
+		switch reqErr.Code() {
+		case gocql.ErrCodeWriteTimeout, gocql.ErrCodeReadTimeout:
+			return errorClassTimeout
+		case gocql.ErrCodeInvalid:
+			if strings.Contains(strings.ToLower(reqErr.Message()), "undefined column") {
+				return errorClassUndefinedColumn
+			}
+		}
+	}
+	return errorClassOther
+}
+
+// errorAction returns the action configured for the error class, errors that don't belong to a configurable class
+// always fail the pipeline.
+func (d *DestinationConfig) errorAction(class errorClass) string {
+	switch class {
+	case errorClassMarshal:
+		return d.ErrorsMarshal
+	case errorClassUndefinedColumn:
+		return d.ErrorsUndefinedColumn
+	case errorClassLWT:
+		return d.ErrorsLWT
+	case errorClassTimeout:
+		return d.ErrorsTimeout
+	default:
+		return ErrorActionFail
+	}
+}
+
+// deadLetterEnabled returns true if any of the error classes is configured to use the dead-letter table.
+func (d *DestinationConfig) deadLetterEnabled() bool {
+	for _, action := range []string{d.ErrorsMarshal, d.ErrorsUndefinedColumn, d.ErrorsLWT, d.ErrorsTimeout} {
+		if action == ErrorActionDeadLetter {
+			return true
+		}
+	}
+	return false
+}
+
+// handleWriteError applies the configured error action to a record that failed to be written. It returns nil if the
+// record was skipped or written to the dead-letter table, otherwise it returns the original error.
+func (d *Destination) handleWriteError(ctx context.Context, record opencdc.Record, err error) error {
+	class := classifyError(err)
+	d.metrics.observeError(record.Operation, class)
+	switch d.config.errorAction(class) {
+	case ErrorActionSkip:
+		sdk.Logger(ctx).Warn().Err(err).
+			Str("errorClass", string(class)).
+			Str("position", string(record.Position)).
+			Msg("skipping record that failed to be written")
+		return nil
+	case ErrorActionDeadLetter:
+		if dlErr := d.writeDeadLetter(ctx, record, class, err); dlErr != nil {
+			return fmt.Errorf("error while writing record to the dead-letter table: %w (original error: %w)", dlErr, err)
+		}
+		sdk.Logger(ctx).Warn().Err(err).
+			Str("errorClass", string(class)).
+			Str("position", string(record.Position)).
+			Msg("record written to the dead-letter table")
+		return nil
+	default:
+		if isRetryableError(err) {
+			sdk.Logger(ctx).Error().Err(err).Msg("transient error persisted after retrying, the pipeline can be restarted once the cluster recovers")
+		} else {
+			sdk.Logger(ctx).Error().Err(err).Msg("fatal error while writing record")
+		}
+		return err
+	}
+}
+
+// createDeadLetterTable creates the dead-letter table if it doesn't exist.
+func (d *Destination) createDeadLetterTable() error {
+	err := d.session.Query(fmt.Sprintf(createDeadLetterTableQuery, d.config.ErrorsDeadLetterTable)).Exec()
+	if err != nil {
+		return fmt.Errorf("error while creating the dead-letter table: %w", err)
+	}
+	return nil
+}
+
+// writeDeadLetter writes the failing record and the error details into the dead-letter table.
+func (d *Destination) writeDeadLetter(ctx context.Context, record opencdc.Record, class errorClass, err error) error {
+	// the table name is only informative here, it might be the reason the record failed
+	table, _ := d.getTableName(record)
+	stmt := Statement{
+		CQL: fmt.Sprintf(insertDeadLetterQuery, d.config.ErrorsDeadLetterTable),
+		Values: []interface{}{
+			table,
+			record.Operation.String(),
+			[]byte(record.Position),
+			string(record.Bytes()),
+			string(class),
+			err.Error(),
+		},
+	}
+	if d.config.DryRun {
+		return d.renderDryRun(ctx, record, stmt)
+	}
+	return d.session.Query(stmt.CQL, stmt.Values...).Exec()
+}
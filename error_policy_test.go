@@ -0,0 +1,85 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+)
+
+func TestClassifyError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want errorClass
+	}{{
+		name: "invalid record",
+		err:  fmt.Errorf("%w: payload should be structured data", errInvalidRecord),
+		want: errorClassMarshal,
+	}, {
+		name: "gocql marshal error",
+		err:  fmt.Errorf("error while inserting data: %w", gocql.MarshalError("can not marshal string into int")),
+		want: errorClassMarshal,
+	}, {
+		name: "lwt not applied",
+		err:  fmt.Errorf("error while inserting data: %w, row already exists", errLWTNotApplied),
+		want: errorClassLWT,
+	}, {
+		name: "write timeout",
+		err:  fmt.Errorf("error while inserting data: %w", &gocql.RequestErrWriteTimeout{}),
+		want: errorClassTimeout,
+	}, {
+		name: "no response",
+		err:  fmt.Errorf("error while deleting data: %w", gocql.ErrTimeoutNoResponse),
+		want: errorClassTimeout,
+	}, {
+		name: "other",
+		err:  errors.New("boom"),
+		want: errorClassOther,
+	},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(classifyError(tt.err), tt.want)
+		})
+	}
+}
+
+func TestConfig_ErrorAction(t *testing.T) {
+	is := is.New(t)
+	cfg := DestinationConfig{
+		ErrorsMarshal:         ErrorActionSkip,
+		ErrorsUndefinedColumn: ErrorActionDeadLetter,
+		ErrorsLWT:             ErrorActionSkip,
+		ErrorsTimeout:         ErrorActionFail,
+	}
+	is.Equal(cfg.errorAction(errorClassMarshal), ErrorActionSkip)
+	is.Equal(cfg.errorAction(errorClassUndefinedColumn), ErrorActionDeadLetter)
+	is.Equal(cfg.errorAction(errorClassTimeout), ErrorActionFail)
+	is.Equal(cfg.errorAction(errorClassOther), ErrorActionFail)
+	is.True(cfg.deadLetterEnabled())
+
+	// dead-letter table is required when the deadletter action is used
+	is.True(cfg.validateConfig() != nil)
+	cfg.ErrorsDeadLetterTable = "dead_letters"
+	is.True(cfg.validateConfig() != nil)
+	cfg.ErrorsDeadLetterTable = "my_keyspace.dead_letters"
+	is.NoErr(cfg.validateConfig())
+}
@@ -0,0 +1,292 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+)
+
+// fanOutTable is a denormalized table the records are written to, in addition to the table.
+type fanOutTable struct {
+	// name is the name of the table, without the keyspace.
+	name string
+	// columns map the columns of the table to the record fields, nil if the fields are written to the columns with
+	// the same name.
+	columns []fanOutColumn
+	// key are the primary key columns of the table.
+	key []string
+}
+
+// fanOutColumn maps a column to a record field, the path of the field is split on dots to map nested fields.
+type fanOutColumn struct {
+	column string
+	path   string
+}
+
+func (d *DestinationConfig) fanOutEnabled() bool {
+	return len(d.FanOut) > 0
+}
+
+// parseFanOut parses the fan-out tables, sorted by name so the statements are always in the same order.
+func (d *DestinationConfig) parseFanOut() ([]fanOutTable, error) {
+	if !d.fanOutEnabled() {
+		return nil, nil
+	}
+	switch {
+	case d.Mode == ModeChangelog:
+		return nil, fmt.Errorf("fanout can't be used in changelog mode")
+	case d.DeleteMode != DeleteModeHard:
+		// soft deletes are lightweight transactions, and ttl deletes read the row, so they can't be batched
+		return nil, fmt.Errorf("fanout requires the %s delete mode", DeleteModeHard)
+	case d.QueryInsert != "" || d.QueryUpdate != "" || d.QueryDelete != "":
+		return nil, fmt.Errorf("fanout can't be used with query templates")
+	}
+
+	tables := make([]fanOutTable, 0, len(d.FanOut))
+	for _, name := range slices.Sorted(maps.Keys(d.FanOut)) {
+		t, err := parseFanOutTable(name, d.FanOut[name])
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+func parseFanOutTable(name string, cfg FanOutTableConfig) (fanOutTable, error) {
+	if !cqlIdentifierRegex.MatchString(name) {
+		return fanOutTable{}, fmt.Errorf("invalid fanout table name %q", name)
+	}
+	t := fanOutTable{name: name}
+	mapped := make(map[string]bool)
+	for _, c := range cfg.Columns {
+		column, path, ok := strings.Cut(c, "=")
+		column = strings.TrimSpace(column)
+		path = strings.TrimSpace(path)
+		if !ok {
+			path = column
+		}
+		if !cqlIdentifierRegex.MatchString(column) || path == "" {
+			return fanOutTable{}, fmt.Errorf("invalid fanout.%s.columns %q, should be in the format column=field", name, c)
+		}
+		if mapped[column] {
+			return fanOutTable{}, fmt.Errorf("column %q is mapped more than once in fanout.%s.columns", column, name)
+		}
+		mapped[column] = true
+		t.columns = append(t.columns, fanOutColumn{column: column, path: path})
+	}
+
+	if len(cfg.Key) == 0 {
+		return fanOutTable{}, fmt.Errorf("fanout.%s.key should be provided", name)
+	}
+	for _, k := range cfg.Key {
+		k = strings.TrimSpace(k)
+		if !cqlIdentifierRegex.MatchString(k) {
+			return fanOutTable{}, fmt.Errorf("invalid fanout.%s.key column %q", name, k)
+		}
+		if t.columns != nil && !mapped[k] {
+			return fanOutTable{}, fmt.Errorf("key column %q should be mapped in fanout.%s.columns", k, name)
+		}
+		t.key = append(t.key, k)
+	}
+	return t, nil
+}
+
+// row returns the primary key and the other columns of the row of the record in the table. The values of the current
+// row are taken from the key, then from the payload after. The values of the old row, before an update or a delete,
+// are taken from the payload before, then from the key. It returns false if a primary key column has no value.
+func (t fanOutTable) row(record opencdc.Record, old bool) (key, columns opencdc.StructuredData, ok bool) {
+	sources := []opencdc.Data{record.Key, record.Payload.After}
+	if old {
+		sources = []opencdc.Data{record.Payload.Before, record.Key}
+	}
+
+	mapping := t.columns
+	if mapping == nil {
+		// all the fields are written to the columns with the same name
+		names := make(map[string]bool)
+		for _, src := range sources {
+			if data, ok := src.(opencdc.StructuredData); ok {
+				for k := range data {
+					names[k] = true
+				}
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(names)) {
+			mapping = append(mapping, fanOutColumn{column: name, path: name})
+		}
+	}
+
+	values := make(opencdc.StructuredData, len(mapping))
+	for _, c := range mapping {
+		for _, src := range sources {
+			if v, ok := lookupPath(src, c.path); ok {
+				values[c.column] = v
+				break
+			}
+		}
+	}
+
+	key = make(opencdc.StructuredData, len(t.key))
+	for _, k := range t.key {
+		v, ok := values[k]
+		if !ok {
+			return nil, nil, false
+		}
+		key[k] = v
+		delete(values, k)
+	}
+	return key, values, true
+}
+
+// changedKey returns the key of the row before an update, taken from the payload before, and whether the update
+// changed it.
+func changedKey(record opencdc.Record) (opencdc.StructuredData, bool) {
+	before, ok := record.Payload.Before.(opencdc.StructuredData)
+	if !ok || record.Operation != opencdc.OperationUpdate {
+		return nil, false
+	}
+	key := record.Key.(opencdc.StructuredData)
+	old := make(opencdc.StructuredData, len(key))
+	changed := false
+	for k, v := range key {
+		old[k] = v
+		if bv, ok := before[k]; ok && !equalValues(bv, v) {
+			old[k] = bv
+			changed = true
+		}
+	}
+	return old, changed
+}
+
+// equalKeys returns true if the keys have the same columns with equal values.
+func equalKeys(a, b opencdc.StructuredData) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		bv, ok := b[k]
+		if !ok || !equalValues(v, bv) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValues returns true if the values are equal. Numbers are compared by value, since the key and the payloads of
+// a record can be decoded differently, ex: int64(1) in the key and float64(1) in the payload before. A spurious key
+// change would delete and insert the row with the same timestamp, and the delete would win.
+func equalValues(a, b interface{}) bool {
+	if x, ok := toInt64(a); ok {
+		if y, ok := toInt64(b); ok {
+			return x == y
+		}
+	}
+	if x, ok := toFloat64(a); ok {
+		if y, ok := toFloat64(b); ok {
+			return x == y
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// writeFanOut writes the record to the table and to the fan-out tables in a logged batch, so either all or none of
+// the tables are updated. If an update changed the key of a row, the old row is deleted in the same batch.
+func (d *Destination) writeFanOut(ctx context.Context, record opencdc.Record, table string) error {
+	for _, m := range []string{metadataCassandraDeleteColumns, metadataCassandraDeleteRange} {
+		if _, ok := record.Metadata[m]; ok {
+			return fmt.Errorf("%w: %s metadata can't be used with fanout", errInvalidRecord, m)
+		}
+	}
+
+	var stmts []Statement
+	switch record.Operation {
+	case opencdc.OperationDelete:
+		stmt, err := d.queryBuilder.BuildDeleteQuery(record, table)
+		if err != nil {
+			return fmt.Errorf("error while deleting data: %w", err)
+		}
+		stmts = append(stmts, stmt)
+	case opencdc.OperationUpdate:
+		if oldKey, ok := changedKey(record); ok {
+			stmt, err := d.queryBuilder.BuildDeleteQuery(opencdc.Record{Operation: opencdc.OperationDelete, Key: oldKey}, table)
+			if err != nil {
+				return fmt.Errorf("error while deleting the old row: %w", err)
+			}
+			stmts = append(stmts, stmt)
+		}
+		stmts = append(stmts, d.queryBuilder.BuildUpdateStatements(record, table)...)
+	default:
+		stmts = append(stmts, d.queryBuilder.BuildInsertQuery(record, table))
+	}
+
+	keyspace, _, _ := strings.Cut(table, ".")
+	fanOut, err := d.fanOutStatements(record, keyspace)
+	if err != nil {
+		return err
+	}
+	// the statements are upserts and deletes, so the batch can be safely retried
+	err = d.exec(ctx, record, table, append(stmts, fanOut...)...)
+	if err != nil {
+		return fmt.Errorf("error while writing data to the fanout tables: %w", err)
+	}
+	return nil
+}
+
+// fanOutStatements returns the statements writing the record to the fan-out tables in the keyspace. The rows are
+// upserted, or deleted for delete records. The old row is deleted first if an update changed its primary key.
+func (d *Destination) fanOutStatements(record opencdc.Record, keyspace string) ([]Statement, error) {
+	var stmts []Statement
+	for _, t := range d.fanOut {
+		table := keyspace + "." + t.name
+		oldKey, _, hasOld := t.row(record, true)
+		if record.Operation == opencdc.OperationDelete {
+			if !hasOld {
+				return nil, fmt.Errorf("%w: the primary key of %s can't be derived from the record", errInvalidRecord, table)
+			}
+			stmt, err := d.queryBuilder.BuildDeleteQuery(opencdc.Record{Operation: opencdc.OperationDelete, Key: oldKey}, table)
+			if err != nil {
+				return nil, fmt.Errorf("error while deleting data from %s: %w", table, err)
+			}
+			stmts = append(stmts, stmt)
+			continue
+		}
+
+		key, columns, ok := t.row(record, false)
+		if !ok {
+			return nil, fmt.Errorf("%w: the primary key of %s can't be derived from the record", errInvalidRecord, table)
+		}
+		if record.Operation == opencdc.OperationUpdate && hasOld && !equalKeys(oldKey, key) {
+			stmt, err := d.queryBuilder.BuildDeleteQuery(opencdc.Record{Operation: opencdc.OperationDelete, Key: oldKey}, table)
+			if err != nil {
+				return nil, fmt.Errorf("error while deleting the old row from %s: %w", table, err)
+			}
+			stmts = append(stmts, stmt)
+		}
+		stmts = append(stmts, d.queryBuilder.BuildInsertQuery(opencdc.Record{
+			Operation: record.Operation,
+			Key:       key,
+			Payload:   opencdc.Change{After: columns},
+		}, table))
+	}
+	return stmts, nil
+}
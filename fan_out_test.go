@@ -0,0 +1,245 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/matryer/is"
+)
+
+func TestDestinationConfig_ParseFanOut(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     DestinationConfig
+		want    []fanOutTable
+		wantErr bool
+	}{
+		{
+			name: "mapped columns",
+			cfg: DestinationConfig{DeleteMode: DeleteModeHard, FanOut: map[string]FanOutTableConfig{
+				"users_by_email": {Columns: []string{"email", " id = key_id", "city=address.city"}, Key: []string{"email"}},
+				"users_by_city":  {Key: []string{"city", "id"}},
+			}},
+			want: []fanOutTable{
+				{name: "users_by_city", key: []string{"city", "id"}},
+				{
+					name: "users_by_email",
+					columns: []fanOutColumn{
+						{column: "email", path: "email"},
+						{column: "id", path: "key_id"},
+						{column: "city", path: "address.city"},
+					},
+					key: []string{"email"},
+				},
+			},
+		},
+		{
+			name:    "missing key",
+			cfg:     DestinationConfig{DeleteMode: DeleteModeHard, FanOut: map[string]FanOutTableConfig{"users_by_email": {}}},
+			wantErr: true,
+		},
+		{
+			name: "unmapped key column",
+			cfg: DestinationConfig{DeleteMode: DeleteModeHard, FanOut: map[string]FanOutTableConfig{
+				"users_by_email": {Columns: []string{"id"}, Key: []string{"email"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate column",
+			cfg: DestinationConfig{DeleteMode: DeleteModeHard, FanOut: map[string]FanOutTableConfig{
+				"users_by_email": {Columns: []string{"email", "email=id"}, Key: []string{"email"}},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid table name",
+			cfg:     DestinationConfig{DeleteMode: DeleteModeHard, FanOut: map[string]FanOutTableConfig{"users-by-email": {Key: []string{"email"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "soft deletes",
+			cfg:     DestinationConfig{DeleteMode: DeleteModeSoft, FanOut: map[string]FanOutTableConfig{"users_by_email": {Key: []string{"email"}}}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			got, err := tc.cfg.parseFanOut()
+			if tc.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(got, tc.want)
+		})
+	}
+}
+
+func TestFanOutTable_Row(t *testing.T) {
+	is := is.New(t)
+	table := fanOutTable{
+		name: "users_by_email",
+		columns: []fanOutColumn{
+			{column: "email", path: "email"},
+			{column: "id", path: "id"},
+			{column: "city", path: "address.city"},
+		},
+		key: []string{"email"},
+	}
+	rec := opencdc.Record{
+		Operation: opencdc.OperationUpdate,
+		Key:       opencdc.StructuredData{"id": json.Number("1")},
+		Payload: opencdc.Change{
+			Before: opencdc.StructuredData{"email": "old@example.com"},
+			After: opencdc.StructuredData{
+				"email":   "new@example.com",
+				"address": map[string]interface{}{"city": "Paris"},
+			},
+		},
+	}
+
+	key, columns, ok := table.row(rec, false)
+	is.True(ok)
+	is.Equal(key, opencdc.StructuredData{"email": "new@example.com"})
+	is.Equal(columns, opencdc.StructuredData{"id": json.Number("1"), "city": "Paris"})
+
+	key, _, ok = table.row(rec, true)
+	is.True(ok)
+	is.Equal(key, opencdc.StructuredData{"email": "old@example.com"})
+
+	// the old key can't be derived without the payload before
+	rec.Payload.Before = nil
+	_, _, ok = table.row(rec, true)
+	is.True(!ok)
+
+	// all the fields are written as is without mappings
+	table.columns = nil
+	key, columns, ok = table.row(rec, false)
+	is.True(ok)
+	is.Equal(key, opencdc.StructuredData{"email": "new@example.com"})
+	is.Equal(columns, opencdc.StructuredData{"id": json.Number("1"), "address": map[string]interface{}{"city": "Paris"}})
+}
+
+func TestChangedKey(t *testing.T) {
+	is := is.New(t)
+	rec := opencdc.Record{
+		Operation: opencdc.OperationUpdate,
+		Key:       opencdc.StructuredData{"tenant": "a", "id": json.Number("2")},
+		Payload: opencdc.Change{
+			Before: opencdc.StructuredData{"id": json.Number("1"), "name": "john"},
+			After:  opencdc.StructuredData{"name": "john"},
+		},
+	}
+	old, changed := changedKey(rec)
+	is.True(changed)
+	is.Equal(old, opencdc.StructuredData{"tenant": "a", "id": json.Number("1")})
+
+	rec.Payload.Before = opencdc.StructuredData{"id": json.Number("2")}
+	_, changed = changedKey(rec)
+	is.True(!changed)
+
+	// numbers are compared by value, whatever their type
+	for _, before := range []interface{}{int64(2), float64(2), json.Number("2.0")} {
+		rec.Payload.Before = opencdc.StructuredData{"id": before}
+		_, changed = changedKey(rec)
+		is.True(!changed)
+	}
+	rec.Payload.Before = opencdc.StructuredData{"id": float64(2.5)}
+	_, changed = changedKey(rec)
+	is.True(changed)
+}
+
+func TestEqualKeys(t *testing.T) {
+	is := is.New(t)
+	is.True(equalKeys(opencdc.StructuredData{"id": int64(1), "tenant": "a"}, opencdc.StructuredData{"id": float64(1), "tenant": "a"}))
+	is.True(equalKeys(opencdc.StructuredData{"id": int32(1)}, opencdc.StructuredData{"id": json.Number("1")}))
+	is.True(!equalKeys(opencdc.StructuredData{"id": int64(1)}, opencdc.StructuredData{"id": float64(1.5)}))
+	is.True(!equalKeys(opencdc.StructuredData{"id": int64(1)}, opencdc.StructuredData{"id": "1"}))
+	is.True(!equalKeys(opencdc.StructuredData{"id": int64(1)}, opencdc.StructuredData{"id": int64(1), "tenant": "a"}))
+}
+
+func TestDestination_FanOutDryRun(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	output := filepath.Join(t.TempDir(), "dry_run.cql")
+
+	d := &Destination{}
+	err := d.Configure(ctx, map[string]string{
+		"nodes":                         "localhost:9042",
+		"keyspace":                      "ks",
+		"table":                         "users_by_id",
+		"dryRun":                        "true",
+		"dryRun.output":                 output,
+		"fanout.users_by_email.columns": "email,id,name",
+		"fanout.users_by_email.key":     "email",
+	})
+	is.NoErr(err)
+	is.NoErr(d.Open(ctx))
+
+	_, err = d.Write(ctx, []opencdc.Record{
+		{
+			Operation: opencdc.OperationCreate,
+			Key:       opencdc.StructuredData{"id": json.Number("1")},
+			Payload:   opencdc.Change{After: opencdc.StructuredData{"email": "john@example.com", "name": "john"}},
+		},
+		{
+			Operation: opencdc.OperationUpdate,
+			Key:       opencdc.StructuredData{"id": json.Number("1")},
+			Payload: opencdc.Change{
+				Before: opencdc.StructuredData{"email": "john@example.com", "name": "john"},
+				After:  opencdc.StructuredData{"email": "johnny@example.com", "name": "john"},
+			},
+		},
+		{
+			Operation: opencdc.OperationDelete,
+			Key:       opencdc.StructuredData{"id": json.Number("1")},
+			Payload:   opencdc.Change{Before: opencdc.StructuredData{"email": "johnny@example.com"}},
+		},
+	})
+	is.NoErr(err)
+
+	// the key of the fan-out table can't be derived from a delete without the payload before
+	_, err = d.Write(ctx, []opencdc.Record{{
+		Operation: opencdc.OperationDelete,
+		Key:       opencdc.StructuredData{"id": json.Number("1")},
+	}})
+	is.True(errors.Is(err, errInvalidRecord))
+	is.NoErr(d.Teardown(ctx))
+
+	got, err := os.ReadFile(output)
+	is.NoErr(err)
+	is.Equal(string(got), "BEGIN BATCH "+
+		"INSERT INTO ks.users_by_id (email, name, id) VALUES ('john@example.com', 'john', 1); "+
+		"INSERT INTO ks.users_by_email (id, name, email) VALUES (1, 'john', 'john@example.com'); "+
+		"APPLY BATCH;\n"+
+		"BEGIN BATCH "+
+		"UPDATE ks.users_by_id SET email = 'johnny@example.com' , name = 'john' WHERE id = 1; "+
+		"DELETE FROM ks.users_by_email WHERE email = 'john@example.com'; "+
+		"INSERT INTO ks.users_by_email (id, name, email) VALUES (1, 'john', 'johnny@example.com'); "+
+		"APPLY BATCH;\n"+
+		"BEGIN BATCH "+
+		"DELETE FROM ks.users_by_id WHERE id = 1; "+
+		"DELETE FROM ks.users_by_email WHERE email = 'johnny@example.com'; "+
+		"APPLY BATCH;\n")
+}
@@ -0,0 +1,174 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+)
+
+const (
+	GeneratorNow         = "now()"
+	GeneratorUUID        = "uuid()"
+	GeneratorToTimestamp = "toTimestamp(now())"
+	// generatorHashPrefix starts the hash generator, ex: hash(name|email).
+	generatorHashPrefix = "hash("
+)
+
+// cqlFunction is a value generated by a CQL function, it's rendered as the function in the query instead of being
+// bound to a placeholder.
+type cqlFunction string
+
+// generator fills a missing column with a generated value.
+type generator struct {
+	column string
+	// function is the CQL function generating the value, empty for the hash generator.
+	function string
+	// fields are the payload fields hashed by the hash generator.
+	fields []string
+}
+
+// value returns the generated value for the record. Values of CQL functions are rendered in the query.
+func (g generator) value(record opencdc.Record) (interface{}, error) {
+	if g.function == "" {
+		return g.hash(record)
+	}
+	return cqlFunction(g.function), nil
+}
+
+// hash returns the hex encoded SHA-256 hash of the JSON array of the field values, fields are looked up in the
+// payload, then in the key.
+func (g generator) hash(record opencdc.Record) (interface{}, error) {
+	values := make([]interface{}, len(g.fields))
+	for i, f := range g.fields {
+		v, ok := fieldValue(f, record.Payload.After, record.Key)
+		if !ok {
+			return nil, fmt.Errorf("%w: field %q hashed into column %q not found", errInvalidRecord, f, g.column)
+		}
+		values[i] = v
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error while hashing fields into column %q: %w", errInvalidRecord, g.column, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// parseGenerators parses the generators configuration, a list of column=generator pairs. CQL functions can't be used
+// with replay protection: a generated key column changes on every delivery, so a replayed record would be written to
+// a new row and checkpointed in a new partition instead of being skipped.
+func (d *DestinationConfig) parseGenerators() ([]generator, error) {
+	generators := make([]generator, 0, len(d.Generators))
+	for _, entry := range d.Generators {
+		column, function, ok := strings.Cut(entry, "=")
+		column, function = strings.TrimSpace(column), strings.TrimSpace(function)
+		if !ok || !cqlIdentifierRegex.MatchString(column) {
+			return nil, fmt.Errorf("invalid generators format %q, should be column=generator", entry)
+		}
+		g := generator{column: column}
+		switch {
+		case function == GeneratorNow, function == GeneratorUUID, function == GeneratorToTimestamp:
+			if d.checkpointEnabled() {
+				return nil, fmt.Errorf("generator %s of column %q can't be used with checkpoint.table, only hash is supported", function, column)
+			}
+			g.function = function
+		case strings.HasPrefix(function, generatorHashPrefix) && strings.HasSuffix(function, ")"):
+			for _, f := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(function, generatorHashPrefix), ")"), "|") {
+				if f = strings.TrimSpace(f); f != "" {
+					g.fields = append(g.fields, f)
+				}
+			}
+			if len(g.fields) == 0 {
+				return nil, fmt.Errorf("invalid generator %q, hash should have at least one field", function)
+			}
+		default:
+			return nil, fmt.Errorf("unknown generator %q, should be one of %s, %s, %s or hash(field|...)", function,
+				GeneratorNow, GeneratorUUID, GeneratorToTimestamp)
+		}
+		generators = append(generators, g)
+	}
+	return generators, nil
+}
+
+// generateValues returns the record with the missing columns filled by the generators. A generated column is added to
+// the key if it's part of the table primary key, otherwise to the payload. Key columns are only generated for
+// inserts, since updates and deletes need the key of an existing row. The key and payload are copied, so the original
+// record is left unchanged.
+func (d *Destination) generateValues(record opencdc.Record, table string) (opencdc.Record, error) {
+	if record.Operation == opencdc.OperationDelete {
+		return record, nil
+	}
+	var meta *gocql.TableMetadata
+	if d.queryBuilder.tables != nil {
+		meta, _ = d.queryBuilder.tables(table)
+	}
+	insert := record.Operation == opencdc.OperationCreate || record.Operation == opencdc.OperationSnapshot
+
+	key := copyStructuredData(record.Key.(opencdc.StructuredData))
+	payload := copyStructuredData(record.Payload.After.(opencdc.StructuredData))
+	for _, g := range d.generators {
+		if _, ok := key[g.column]; ok {
+			continue
+		}
+		if _, ok := payload[g.column]; ok {
+			continue
+		}
+		inKey := meta != nil && isPrimaryKey(meta, g.column)
+		if inKey && !insert {
+			continue
+		}
+		value, err := g.value(record)
+		if err != nil {
+			return record, err
+		}
+		if inKey {
+			key[g.column] = value
+		} else {
+			payload[g.column] = value
+		}
+	}
+	record.Key = key
+	record.Payload.After = payload
+	return record, nil
+}
+
+// fieldValue returns the value of the field from the first structured data that contains it.
+func fieldValue(field string, data ...opencdc.Data) (interface{}, bool) {
+	for _, d := range data {
+		sd, ok := d.(opencdc.StructuredData)
+		if !ok {
+			continue
+		}
+		if v, ok := sd[field]; ok && v != nil {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func copyStructuredData(data opencdc.StructuredData) opencdc.StructuredData {
+	c := make(opencdc.StructuredData, len(data))
+	for k, v := range data {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,109 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/matryer/is"
+)
+
+func TestConfig_Generators(t *testing.T) {
+	testCases := []struct {
+		name       string
+		generators []string
+		checkpoint bool
+		wantErr    bool
+	}{{
+		name:       "valid",
+		generators: []string{"seq=now()", "uid = uuid()", "updated=toTimestamp(now())", "hash=hash(name|email)"},
+	}, {
+		name:       "unknown generator",
+		generators: []string{"seq=now"},
+		wantErr:    true,
+	}, {
+		name:       "hash without fields",
+		generators: []string{"hash=hash()"},
+		wantErr:    true,
+	}, {
+		name:       "missing column",
+		generators: []string{"now()"},
+		wantErr:    true,
+	}, {
+		name:       "function with replay protection",
+		checkpoint: true,
+		generators: []string{"seq=now()"},
+		wantErr:    true,
+	}, {
+		name:       "hash with replay protection",
+		checkpoint: true,
+		generators: []string{"hash=hash(name|email)"},
+	}}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			cfg := DestinationConfig{Generators: tt.generators}
+			if tt.checkpoint {
+				cfg.CheckpointTable = "ks.checkpoints"
+				cfg.CheckpointPositionOrder = CheckpointPositionOrderNumber
+			}
+			_, err := cfg.parseConfig()
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+		})
+	}
+}
+
+func TestDestination_GenerateValues(t *testing.T) {
+	is := is.New(t)
+	cfg := DestinationConfig{Generators: []string{"seq=now()", "name=uuid()", "age=hash(id|email)", "unknown=toTimestamp(now())"}}
+	generators, err := cfg.parseGenerators()
+	is.NoErr(err)
+	d := Destination{config: cfg, generators: generators, queryBuilder: QueryBuilder{tables: testTableMetadata}}
+	rec := opencdc.Record{
+		Operation: opencdc.OperationCreate,
+		Key:       opencdc.StructuredData{"tenant": "acme", "id": 1, "created": 3},
+		Payload: opencdc.Change{
+			After: opencdc.StructuredData{"email": "john@example.com", "name": "john"},
+		},
+	}
+
+	got, err := d.generateValues(rec, "ks.t")
+	is.NoErr(err)
+	// the clustering column is added to the key, existing columns are not overridden
+	is.Equal(got.Key.(opencdc.StructuredData)["seq"], cqlFunction(GeneratorNow))
+	is.Equal(got.Payload.After.(opencdc.StructuredData)["name"], "john")
+	is.Equal(got.Payload.After.(opencdc.StructuredData)["age"], "e84023ad2e38352deaf70efd0c453d598ebaab69c48c747f2ade61bb0f075453")
+	is.Equal(got.Payload.After.(opencdc.StructuredData)["unknown"], cqlFunction(GeneratorToTimestamp))
+	_, ok := rec.Key.(opencdc.StructuredData)["seq"]
+	is.True(!ok) // the original record is left unchanged
+
+	stmt := d.queryBuilder.BuildInsertQuery(got, "ks.t")
+	is.Equal(stmt.CQL, "INSERT INTO ks.t (age, name, email, unknown, tenant, id, created, seq) VALUES (?, ?, ?, toTimestamp(now()), ?, ?, ?, now()) IF NOT EXISTS")
+	is.Equal(stmt.Columns, []string{"age", "name", "email", "tenant", "id", "created"})
+
+	// key columns are not generated for updates
+	rec.Operation = opencdc.OperationUpdate
+	got, err = d.generateValues(rec, "ks.t")
+	is.NoErr(err)
+	_, ok = got.Key.(opencdc.StructuredData)["seq"]
+	is.True(!ok)
+	stmt = d.queryBuilder.BuildUpdateQuery(got, "ks.t")
+	is.Equal(stmt.CQL, "UPDATE ks.t SET age = ? , name = ? , email = ? , unknown = toTimestamp(now()) WHERE tenant = ? AND id = ? AND created = ? IF EXISTS")
+}
@@ -0,0 +1,86 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/gocql/gocql"
+)
+
+const (
+	HostSelectionRoundRobin = "roundRobin"
+	HostSelectionDCAware    = "dcAware"
+	HostSelectionRackAware  = "rackAware"
+	HostSelectionTokenAware = "tokenAware"
+)
+
+// newHostSelectionPolicy returns the host selection policy configured for the connector. The token aware policy
+// routes queries to the replicas owning the partition, and falls back to the DC or rack aware policy if a local DC is
+// configured, otherwise it falls back to round-robin.
+func (d *DestinationConfig) newHostSelectionPolicy() gocql.HostSelectionPolicy {
+	switch d.HostSelection {
+	case HostSelectionDCAware:
+		return gocql.DCAwareRoundRobinPolicy(d.LocalDC)
+	case HostSelectionRackAware:
+		return gocql.RackAwareRoundRobinPolicy(d.LocalDC, d.LocalRack)
+	case HostSelectionTokenAware:
+		fallback := gocql.RoundRobinHostPolicy()
+		switch {
+		case d.LocalDC != "" && d.LocalRack != "":
+			fallback = gocql.RackAwareRoundRobinPolicy(d.LocalDC, d.LocalRack)
+		case d.LocalDC != "":
+			fallback = gocql.DCAwareRoundRobinPolicy(d.LocalDC)
+		}
+		if d.ShuffleReplicas {
+			return gocql.TokenAwareHostPolicy(fallback, gocql.ShuffleReplicas())
+		}
+		return gocql.TokenAwareHostPolicy(fallback)
+	default:
+		return gocql.RoundRobinHostPolicy()
+	}
+}
+
+// newHostFilter returns a host filter that only accepts the hosts in the allow-list, or nil if the allow-list is
+// empty. Hosts with a port are only accepted on that port, hosts without a port are accepted on any port. Hostnames are
+// resolved once, when the filter is created.
+func (d *DestinationConfig) newHostFilter(ctx context.Context) (gocql.HostFilter, error) {
+	if len(d.HostAllowList) == 0 {
+		return nil, nil
+	}
+	allowed := make(map[string]bool)
+	for _, h := range d.HostAllowList {
+		host, port := h, ""
+		if splitHost, splitPort, err := net.SplitHostPort(h); err == nil {
+			host, port = splitHost, splitPort
+		}
+		ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve allowed host %q: %w", h, err)
+		}
+		for _, ip := range ips {
+			if port == "" {
+				allowed[ip.String()] = true
+			} else {
+				allowed[net.JoinHostPort(ip.String(), port)] = true
+			}
+		}
+	}
+	return gocql.HostFilterFunc(func(host *gocql.HostInfo) bool {
+		return allowed[host.ConnectAddress().String()] || allowed[host.ConnectAddressAndPort()]
+	}), nil
+}
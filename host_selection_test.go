@@ -0,0 +1,92 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+)
+
+func TestConfig_HostSelection(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  DestinationConfig
+		wantErr bool
+	}{{
+		name:    "dcAware without localDC",
+		config:  DestinationConfig{HostSelection: HostSelectionDCAware},
+		wantErr: true,
+	}, {
+		name:    "rackAware without localRack",
+		config:  DestinationConfig{HostSelection: HostSelectionRackAware, LocalDC: "dc1"},
+		wantErr: true,
+	}, {
+		name:    "shuffleReplicas without tokenAware",
+		config:  DestinationConfig{HostSelection: HostSelectionRoundRobin, ShuffleReplicas: true},
+		wantErr: true,
+	}, {
+		name:    "invalid allow-list host",
+		config:  DestinationConfig{HostSelection: HostSelectionRoundRobin, HostAllowList: []string{"127.0.0.1:0"}},
+		wantErr: true,
+	}, {
+		name: "tokenAware with shuffled replicas",
+		config: DestinationConfig{
+			HostSelection:   HostSelectionTokenAware,
+			LocalDC:         "dc1",
+			ShuffleReplicas: true,
+			HostAllowList:   []string{"127.0.0.1", "localhost:9042"},
+		},
+		wantErr: false,
+	},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			err := tt.config.validateConfig()
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.True(tt.config.newHostSelectionPolicy() != nil)
+		})
+	}
+}
+
+func TestConfig_HostFilter(t *testing.T) {
+	is := is.New(t)
+	cfg := DestinationConfig{}
+	filter, err := cfg.newHostFilter(context.Background())
+	is.NoErr(err)
+	is.Equal(filter, nil)
+
+	cfg.HostAllowList = []string{"127.0.0.1", "127.0.0.2:9042", "127.0.0.3:0"}
+	filter, err = cfg.newHostFilter(context.Background())
+	is.NoErr(err)
+	is.True(filter != nil)
+
+	// the hosts are created without a port, so they are on the port 0
+	host := func(ip string) *gocql.HostInfo {
+		return (&gocql.HostInfo{}).SetConnectAddress(net.ParseIP(ip))
+	}
+	is.True(filter.Accept(host("127.0.0.1")))  // any port
+	is.True(!filter.Accept(host("127.0.0.2"))) // only on the port 9042
+	is.True(filter.Accept(host("127.0.0.3")))  // on the port 0
+	is.True(!filter.Accept(host("127.0.0.4"))) // not allowed
+}
@@ -0,0 +1,210 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/gocql/gocql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "cassandra_destination"
+
+// destinationMetrics collects the metrics of the destination in its own registry. The Conduit SDK doesn't provide a
+// metrics facility to connectors, so the registry is exposed by an HTTP server in the Prometheus format. It implements
+// gocql.QueryObserver and gocql.BatchObserver to observe the statements sent to the cluster. All the methods can be
+// called on a nil value, then nothing is collected.
+type destinationMetrics struct {
+	registry *prometheus.Registry
+
+	recordsWritten   *prometheus.CounterVec
+	recordDuration   *prometheus.HistogramVec
+	batchSize        prometheus.Histogram
+	errors           *prometheus.CounterVec
+	lwtNotApplied    *prometheus.CounterVec
+	queryDuration    *prometheus.HistogramVec
+	retries          *prometheus.CounterVec
+	preparedStmts    *prometheus.CounterVec
+	preparedStmtsMu  sync.Mutex
+	preparedStmtsSet map[string]bool
+
+	server   *http.Server
+	listener net.Listener
+}
+
+func newDestinationMetrics() *destinationMetrics {
+	m := &destinationMetrics{
+		registry: prometheus.NewRegistry(),
+		recordsWritten: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "records_written_total",
+			Help:      "Number of records written, by operation and table.",
+		}, []string{"operation", "table"}),
+		recordDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "record_write_duration_seconds",
+			Help:      "Time spent writing a record, by operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		batchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "batch_size",
+			Help:      "Number of records passed to a single write.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "errors_total",
+			Help:      "Number of records that failed to be written, by error class.",
+		}, []string{"class"}),
+		lwtNotApplied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "lwt_not_applied_total",
+			Help:      "Number of lightweight transactions that were not applied, by operation.",
+		}, []string{"operation"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_duration_seconds",
+			Help:      "Time spent executing a query or a batch on the cluster, by type (query or batch).",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "retries_total",
+			Help:      "Number of queries or batches that were retried, by type (query or batch).",
+		}, []string{"type"}),
+		preparedStmts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "prepared_statements_total",
+			Help:      "Number of statement executions, by whether the statement was already prepared on the host (hit or miss).",
+		}, []string{"result"}),
+		preparedStmtsSet: make(map[string]bool),
+	}
+	m.registry.MustRegister(m.recordsWritten, m.recordDuration, m.batchSize, m.errors, m.lwtNotApplied,
+		m.queryDuration, m.retries, m.preparedStmts)
+	return m
+}
+
+// serve exposes the metrics on the address in the Prometheus format, at the /metrics path.
+func (m *destinationMetrics) serve(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("error while listening on the metrics address: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	m.listener = listener
+	m.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		err := m.server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			sdk.Logger(ctx).Error().Err(err).Msg("metrics server stopped")
+		}
+	}()
+	sdk.Logger(ctx).Info().Str("address", listener.Addr().String()).Msg("serving metrics")
+	return nil
+}
+
+func (m *destinationMetrics) close(ctx context.Context) error {
+	if m == nil || m.server == nil {
+		return nil
+	}
+	return m.server.Shutdown(ctx)
+}
+
+// observeBatch records the number of records passed to a single write.
+func (m *destinationMetrics) observeBatch(records []opencdc.Record) {
+	if m == nil {
+		return
+	}
+	m.batchSize.Observe(float64(len(records)))
+}
+
+// observeRecord records the time spent writing a record, and counts it as written if it succeeded.
+func (m *destinationMetrics) observeRecord(operation opencdc.Operation, table string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.recordDuration.WithLabelValues(operation.String()).Observe(duration.Seconds())
+	if err == nil {
+		m.recordsWritten.WithLabelValues(operation.String(), table).Inc()
+	}
+}
+
+// observeError counts a record that failed to be written.
+func (m *destinationMetrics) observeError(operation opencdc.Operation, class errorClass) {
+	if m == nil {
+		return
+	}
+	m.errors.WithLabelValues(string(class)).Inc()
+	if class == errorClassLWT {
+		m.lwtNotApplied.WithLabelValues(operation.String()).Inc()
+	}
+}
+
+func (m *destinationMetrics) ObserveQuery(_ context.Context, q gocql.ObservedQuery) {
+	if m == nil {
+		return
+	}
+	m.observeStatement("query", q.End.Sub(q.Start), q.Attempt)
+	m.observePrepared(q.Host, q.Keyspace, q.Statement)
+}
+
+func (m *destinationMetrics) ObserveBatch(_ context.Context, b gocql.ObservedBatch) {
+	if m == nil {
+		return
+	}
+	m.observeStatement("batch", b.End.Sub(b.Start), b.Attempt)
+	for _, stmt := range b.Statements {
+		m.observePrepared(b.Host, b.Keyspace, stmt)
+	}
+}
+
+// observeStatement records the duration of an attempt at executing a query or a batch, attempts after the first one
+// are retries.
+func (m *destinationMetrics) observeStatement(typ string, duration time.Duration, attempt int) {
+	m.queryDuration.WithLabelValues(typ).Observe(duration.Seconds())
+	if attempt > 0 {
+		m.retries.WithLabelValues(typ).Inc()
+	}
+}
+
+// observePrepared counts a statement execution as a prepared statement cache hit or miss. gocql doesn't expose its
+// cache, so statements are assumed to stay prepared on a host once they were executed on it.
+func (m *destinationMetrics) observePrepared(host *gocql.HostInfo, keyspace, stmt string) {
+	if host == nil {
+		return
+	}
+	key := host.HostID() + "/" + keyspace + "/" + stmt
+	m.preparedStmtsMu.Lock()
+	hit := m.preparedStmtsSet[key]
+	m.preparedStmtsSet[key] = true
+	m.preparedStmtsMu.Unlock()
+	if hit {
+		m.preparedStmts.WithLabelValues("hit").Inc()
+	} else {
+		m.preparedStmts.WithLabelValues("miss").Inc()
+	}
+}
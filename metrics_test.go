@@ -0,0 +1,90 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDestinationMetrics(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	m := newDestinationMetrics()
+
+	m.observeBatch(make([]opencdc.Record, 3))
+	m.observeRecord(opencdc.OperationCreate, "ks.users", time.Millisecond, nil)
+	m.observeRecord(opencdc.OperationCreate, "ks.users", time.Millisecond, nil)
+	m.observeRecord(opencdc.OperationUpdate, "ks.users", time.Millisecond, errLWTNotApplied)
+	m.observeError(opencdc.OperationUpdate, errorClassLWT)
+	m.observeError(opencdc.OperationCreate, errorClassTimeout)
+
+	is.Equal(testutil.ToFloat64(m.recordsWritten.WithLabelValues("create", "ks.users")), float64(2))
+	is.Equal(testutil.ToFloat64(m.recordsWritten.WithLabelValues("update", "ks.users")), float64(0))
+	is.Equal(testutil.CollectAndCount(m.recordDuration), 2)
+	is.Equal(testutil.CollectAndCount(m.batchSize), 1)
+	is.Equal(testutil.ToFloat64(m.errors.WithLabelValues("lwt")), float64(1))
+	is.Equal(testutil.ToFloat64(m.errors.WithLabelValues("timeout")), float64(1))
+	is.Equal(testutil.ToFloat64(m.lwtNotApplied.WithLabelValues("update")), float64(1))
+
+	host := &gocql.HostInfo{}
+	start := time.Now()
+	m.ObserveQuery(ctx, gocql.ObservedQuery{Keyspace: "ks", Statement: "INSERT", Host: host, Start: start, End: start.Add(time.Millisecond)})
+	m.ObserveQuery(ctx, gocql.ObservedQuery{Keyspace: "ks", Statement: "INSERT", Host: host, Attempt: 1, Err: errors.New("timeout")})
+	m.ObserveBatch(ctx, gocql.ObservedBatch{Keyspace: "ks", Statements: []string{"INSERT", "UPDATE"}, Host: host})
+
+	is.Equal(testutil.ToFloat64(m.retries.WithLabelValues("query")), float64(1))
+	is.Equal(testutil.ToFloat64(m.retries.WithLabelValues("batch")), float64(0))
+	is.Equal(testutil.ToFloat64(m.preparedStmts.WithLabelValues("hit")), float64(2))
+	is.Equal(testutil.ToFloat64(m.preparedStmts.WithLabelValues("miss")), float64(2))
+}
+
+func TestDestinationMetrics_Nil(t *testing.T) {
+	var m *destinationMetrics
+	// doesn't panic
+	m.observeBatch(nil)
+	m.observeRecord(opencdc.OperationCreate, "ks.users", time.Millisecond, nil)
+	m.observeError(opencdc.OperationCreate, errorClassOther)
+	m.ObserveQuery(context.Background(), gocql.ObservedQuery{})
+	m.ObserveBatch(context.Background(), gocql.ObservedBatch{})
+}
+
+func TestDestinationMetrics_Serve(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	m := newDestinationMetrics()
+	is.NoErr(m.serve(ctx, "127.0.0.1:0"))
+	defer func() {
+		is.NoErr(m.close(ctx))
+	}()
+	m.observeRecord(opencdc.OperationCreate, "ks.users", time.Millisecond, nil)
+
+	resp, err := http.Get("http://" + m.listener.Addr().String() + "/metrics")
+	is.NoErr(err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	is.NoErr(err)
+	is.True(strings.Contains(string(body), `cassandra_destination_records_written_total{operation="create",table="ks.users"} 1`))
+}
@@ -0,0 +1,66 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+)
+
+// observer observes the queries and batches sent to the cluster.
+type observer interface {
+	gocql.QueryObserver
+	gocql.BatchObserver
+}
+
+// observers forwards the observed queries and batches to each observer.
+type observers []observer
+
+func (o observers) ObserveQuery(ctx context.Context, q gocql.ObservedQuery) {
+	for _, obs := range o {
+		obs.ObserveQuery(ctx, q)
+	}
+}
+
+func (o observers) ObserveBatch(ctx context.Context, b gocql.ObservedBatch) {
+	for _, obs := range o {
+		obs.ObserveBatch(ctx, b)
+	}
+}
+
+// statementInfo describes the statement being executed, it's passed to the observers in the context of the query.
+type statementInfo struct {
+	table     string
+	operation opencdc.Operation
+	// consistency returns the consistency level of the statement, which can be downgraded between attempts.
+	consistency func() gocql.Consistency
+}
+
+type statementInfoKey struct{}
+
+func contextWithStatementInfo(ctx context.Context, info *statementInfo) context.Context {
+	return context.WithValue(ctx, statementInfoKey{}, info)
+}
+
+// statementInfoFromContext returns the information of the statement, or an empty one if the context doesn't contain
+// it.
+func statementInfoFromContext(ctx context.Context) *statementInfo {
+	if info, ok := ctx.Value(statementInfoKey{}).(*statementInfo); ok {
+		return info
+	}
+	return &statementInfo{}
+}
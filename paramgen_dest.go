@@ -8,59 +8,604 @@ import (
 )
 
 const (
-	DestinationConfigAuthBasicPassword = "auth.basic.password"
-	DestinationConfigAuthBasicUsername = "auth.basic.username"
-	DestinationConfigAuthMechanism     = "auth.mechanism"
-	DestinationConfigKeyspace          = "keyspace"
-	DestinationConfigNodes             = "nodes"
-	DestinationConfigTable             = "table"
+	DestinationConfigAddressTranslationPortOffset       = "addressTranslation.portOffset"
+	DestinationConfigAddressTranslationStatic           = "addressTranslation.static"
+	DestinationConfigAuthAllowedAuthenticators          = "auth.allowedAuthenticators"
+	DestinationConfigAuthBasicPassword                  = "auth.basic.password"
+	DestinationConfigAuthBasicUsername                  = "auth.basic.username"
+	DestinationConfigAuthCredentialsFile                = "auth.credentialsFile"
+	DestinationConfigAuthMechanism                      = "auth.mechanism"
+	DestinationConfigAuthPasswordEnv                    = "auth.passwordEnv"
+	DestinationConfigAuthToken                          = "auth.token"
+	DestinationConfigAuthTokenEnv                       = "auth.tokenEnv"
+	DestinationConfigAuthUsernameEnv                    = "auth.usernameEnv"
+	DestinationConfigAvroKeySchema                      = "avro.keySchema"
+	DestinationConfigAvroPayloadSchema                  = "avro.payloadSchema"
+	DestinationConfigBucketColumn                       = "bucket.column"
+	DestinationConfigBucketField                        = "bucket.field"
+	DestinationConfigBucketFormat                       = "bucket.format"
+	DestinationConfigBucketSize                         = "bucket.size"
+	DestinationConfigCheckpointPositionField            = "checkpoint.positionField"
+	DestinationConfigCheckpointPositionOrder            = "checkpoint.positionOrder"
+	DestinationConfigCheckpointTable                    = "checkpoint.table"
+	DestinationConfigConnectionCompression              = "connection.compression"
+	DestinationConfigConnectionConnectTimeout           = "connection.connectTimeout"
+	DestinationConfigConnectionDisableInitialHostLookup = "connection.disableInitialHostLookup"
+	DestinationConfigConnectionNumConns                 = "connection.numConns"
+	DestinationConfigConnectionProtoVersion             = "connection.protoVersion"
+	DestinationConfigConnectionReconnectionInterval     = "connection.reconnection.interval"
+	DestinationConfigConnectionReconnectionMaxInterval  = "connection.reconnection.maxInterval"
+	DestinationConfigConnectionReconnectionMaxRetries   = "connection.reconnection.maxRetries"
+	DestinationConfigConnectionReconnectionPolicy       = "connection.reconnection.policy"
+	DestinationConfigConnectionTimeout                  = "connection.timeout"
+	DestinationConfigConnectionWriteTimeout             = "connection.writeTimeout"
+	DestinationConfigDeleteSoftFlagColumn               = "delete.soft.flagColumn"
+	DestinationConfigDeleteSoftTimestampColumn          = "delete.soft.timestampColumn"
+	DestinationConfigDeleteTtl                          = "delete.ttl"
+	DestinationConfigDeleteMode                         = "deleteMode"
+	DestinationConfigDryRun                             = "dryRun"
+	DestinationConfigDryRunOutput                       = "dryRun.output"
+	DestinationConfigErrorsDeadletterTable              = "errors.deadletter.table"
+	DestinationConfigErrorsLwt                          = "errors.lwt"
+	DestinationConfigErrorsMarshal                      = "errors.marshal"
+	DestinationConfigErrorsTimeout                      = "errors.timeout"
+	DestinationConfigErrorsUndefinedColumn              = "errors.undefinedColumn"
+	DestinationConfigFanoutColumns                      = "fanout.*.columns"
+	DestinationConfigFanoutKey                          = "fanout.*.key"
+	DestinationConfigGenerators                         = "generators"
+	DestinationConfigHostAllowList                      = "hostAllowList"
+	DestinationConfigHostSelection                      = "hostSelection"
+	DestinationConfigInputFormat                        = "input.format"
+	DestinationConfigKeyspace                           = "keyspace"
+	DestinationConfigLocalDC                            = "localDC"
+	DestinationConfigLocalRack                          = "localRack"
+	DestinationConfigMetricsAddress                     = "metrics.address"
+	DestinationConfigMode                               = "mode"
+	DestinationConfigNodes                              = "nodes"
+	DestinationConfigNullValues                         = "nullValues"
+	DestinationConfigOtelEndpoint                       = "otel.endpoint"
+	DestinationConfigOtelExporter                       = "otel.exporter"
+	DestinationConfigOtelInsecure                       = "otel.insecure"
+	DestinationConfigQueryDelete                        = "query.delete"
+	DestinationConfigQueryIdempotent                    = "query.idempotent"
+	DestinationConfigQueryInsert                        = "query.insert"
+	DestinationConfigQueryUpdate                        = "query.update"
+	DestinationConfigRateLimitAdaptive                  = "rateLimit.adaptive"
+	DestinationConfigRateLimitAdaptiveMinRecords        = "rateLimit.adaptive.minRecords"
+	DestinationConfigRateLimitBytes                     = "rateLimit.bytes"
+	DestinationConfigRateLimitRecords                   = "rateLimit.records"
+	DestinationConfigRetryDowngradingConsistency        = "retry.downgradingConsistency"
+	DestinationConfigRetryMaxBackoff                    = "retry.maxBackoff"
+	DestinationConfigRetryMaxRetries                    = "retry.maxRetries"
+	DestinationConfigRetryMinBackoff                    = "retry.minBackoff"
+	DestinationConfigSchemaAutoCreate                   = "schema.autoCreate"
+	DestinationConfigSchemaEnabled                      = "schema.enabled"
+	DestinationConfigSchemaEvolve                       = "schema.evolve"
+	DestinationConfigSchemaValidate                     = "schema.validate"
+	DestinationConfigShuffleReplicas                    = "shuffleReplicas"
+	DestinationConfigSlowQueryThreshold                 = "slowQuery.threshold"
+	DestinationConfigTable                              = "table"
+	DestinationConfigTracingSampleRate                  = "tracing.sampleRate"
 )
 
 func (DestinationConfig) Parameters() map[string]config.Parameter {
 	return map[string]config.Parameter{
+		DestinationConfigAddressTranslationPortOffset: {
+			Default:     "0",
+			Description: "Offset added to the port of every node address (after applying the static translations), ex: 10000 maps the port\n9042 to 19042.",
+			Type:        config.ParameterTypeInt,
+			Validations: []config.Validation{
+				config.ValidationGreaterThan{V: -65536},
+				config.ValidationLessThan{V: 65536},
+			},
+		},
+		DestinationConfigAddressTranslationStatic: {
+			Default:     "",
+			Description: "Comma separated list of address translations in the format internal=external, used to reach nodes that\nadvertise addresses not reachable by the connector. The internal address is an IP with an optional port, the\nexternal address is a host with an optional port, ex: 10.0.0.1=203.0.113.1,10.0.0.2:9042=cassandra-2.local:19042",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigAuthAllowedAuthenticators: {
+			Default:     "",
+			Description: "Comma separated list of authenticator classes the connector is allowed to authenticate with, ex:\ncom.datastax.bdp.cassandra.auth.DseAuthenticator. If empty, the basic and token mechanisms allow a default list\nof known password authenticators, and the plain mechanism allows any authenticator.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
 		DestinationConfigAuthBasicPassword: {
 			Default:     "",
-			Description: "Password, only if basic auth is used.",
+			Description: "Password, only if basic or plain auth is used.",
 			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{},
 		},
 		DestinationConfigAuthBasicUsername: {
 			Default:     "",
-			Description: "Username, only if basic auth is used.",
+			Description: "Username, only if basic or plain auth is used.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigAuthCredentialsFile: {
+			Default:     "",
+			Description: "Path to a file containing the credentials, one key=value pair per line where the key is one of username,\npassword or token. The file is read each time a connection is opened, so rotated credentials are picked up\nwhen reconnecting. Credentials in the file take precedence over the other configurations.",
 			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{},
 		},
 		DestinationConfigAuthMechanism: {
 			Default:     "none",
-			Description: "Authentication mechanism used by Cassandra.",
+			Description: "Authentication mechanism used by Cassandra, one of: none, basic, plain, token.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"none", "basic", "plain", "token"}},
+			},
+		},
+		DestinationConfigAuthPasswordEnv: {
+			Default:     "",
+			Description: "Name of the environment variable containing the password, takes precedence over auth.basic.password.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigAuthToken: {
+			Default:     "",
+			Description: "Token, only if token auth is used.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigAuthTokenEnv: {
+			Default:     "",
+			Description: "Name of the environment variable containing the token, takes precedence over auth.token.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigAuthUsernameEnv: {
+			Default:     "",
+			Description: "Name of the environment variable containing the username, takes precedence over auth.basic.username.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigAvroKeySchema: {
+			Default:     "",
+			Description: "Avro record schema (JSON) used to decode raw keys that don't reference a schema in their metadata.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigAvroPayloadSchema: {
+			Default:     "",
+			Description: "Avro record schema (JSON) used to decode raw payloads that don't reference a schema in their metadata.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigBucketColumn: {
+			Default:     "",
+			Description: "Name of the key column containing the time bucket of the record, used to split large partitions by time. The\nbucket is derived from bucket.field and added to the key of each record. Time buckets are disabled if empty.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigBucketField: {
+			Default:     "",
+			Description: "Timestamp field the time bucket is derived from, looked up in the key, then in the payload. The field should be\na timestamp in RFC3339 format or milliseconds since epoch. If empty, the opencdc.readAt metadata is used, and\nupdate and delete records are rejected.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigBucketFormat: {
+			Default:     "",
+			Description: "Go time layout used to format the time bucket, ex: 2006-01-02. Defaults to 2006-01-02T15 for hour buckets,\n2006-01-02 for day buckets and 2006-01 for month buckets. The timestamp is truncated to the bucket size before\nit's formatted.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigBucketSize: {
+			Default:     "day",
+			Description: "Size of the time buckets, one of: hour, day, month.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"hour", "day", "month"}},
+			},
+		},
+		DestinationConfigCheckpointPositionField: {
+			Default:     "",
+			Description: "Field of the JSON positions containing the number the positions are ordered by, nested fields are separated by\ndots, ex: offset. Required by the field position order.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigCheckpointPositionOrder: {
+			Default:     "",
+			Description: "How the positions of the records are ordered, required if checkpoint.table is set, one of: bytes (positions\nare compared byte by byte), number (positions are decimal numbers), field (positions are JSON objects ordered\nby the number in checkpoint.positionField).",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"bytes", "number", "field"}},
+			},
+		},
+		DestinationConfigCheckpointTable: {
+			Default:     "",
+			Description: "Fully qualified name (keyspace.table) of the checkpoint table, used to skip replayed records. The last applied\nposition is stored for each partition, and records with a position lower than or equal to it are skipped. The\ntable is created if it doesn't exist. Counter tables and the now(), uuid() and toTimestamp(now()) generators are\nunsupported. Replay protection is disabled if empty.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigConnectionCompression: {
+			Default:     "none",
+			Description: "Compression algorithm used for the frames sent to the cluster, one of: none, snappy, lz4.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"none", "snappy", "lz4"}},
+			},
+		},
+		DestinationConfigConnectionConnectTimeout: {
+			Default:     "11s",
+			Description: "Timeout for establishing a connection to a node.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigConnectionDisableInitialHostLookup: {
+			Default:     "false",
+			Description: "Whether to skip discovering the cluster nodes when connecting, and only use the configured nodes. Needed when\nthe nodes advertise addresses that are not reachable from the connector, ex: behind NAT or in Kubernetes.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigConnectionNumConns: {
+			Default:     "2",
+			Description: "Number of connections opened to each node.",
+			Type:        config.ParameterTypeInt,
+			Validations: []config.Validation{
+				config.ValidationGreaterThan{V: 0},
+			},
+		},
+		DestinationConfigConnectionProtoVersion: {
+			Default:     "0",
+			Description: "Native protocol version used to communicate with the cluster, 0 means it's discovered when connecting.",
+			Type:        config.ParameterTypeInt,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"0", "3", "4"}},
+			},
+		},
+		DestinationConfigConnectionReconnectionInterval: {
+			Default:     "1s",
+			Description: "Interval between reconnection attempts, it's the initial interval for the exponential policy.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigConnectionReconnectionMaxInterval: {
+			Default:     "1m",
+			Description: "Maximum interval between reconnection attempts, only used by the exponential policy.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigConnectionReconnectionMaxRetries: {
+			Default:     "3",
+			Description: "Maximum number of reconnection attempts to a node that went down.",
+			Type:        config.ParameterTypeInt,
+			Validations: []config.Validation{
+				config.ValidationGreaterThan{V: -1},
+			},
+		},
+		DestinationConfigConnectionReconnectionPolicy: {
+			Default:     "constant",
+			Description: "Policy used to reconnect to a node that went down, one of: constant, exponential.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"constant", "exponential"}},
+			},
+		},
+		DestinationConfigConnectionTimeout: {
+			Default:     "11s",
+			Description: "Timeout for a query to return a response.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigConnectionWriteTimeout: {
+			Default:     "11s",
+			Description: "Timeout for writing a query to a connection.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigDeleteSoftFlagColumn: {
+			Default:     "deleted",
+			Description: "Boolean column set to true when a row is soft deleted.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigDeleteSoftTimestampColumn: {
+			Default:     "deleted_at",
+			Description: "Timestamp column set to the time the record was read when a row is soft deleted, it's not set if empty.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigDeleteTtl: {
+			Default:     "1h",
+			Description: "Time to live of the rows re-written by the ttl delete mode.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigDeleteMode: {
+			Default:     "hard",
+			Description: "How delete records are applied, one of: hard (the row is deleted), soft (the row is marked as deleted by\nupdating delete.soft.flagColumn and delete.soft.timestampColumn), ttl (the row is re-written with delete.ttl so\nit expires).",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"hard", "soft", "ttl"}},
+			},
+		},
+		DestinationConfigDryRun: {
+			Default:     "false",
+			Description: "Whether to render the statements of each record instead of executing them, the connector doesn't connect to the\ncluster.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigDryRunOutput: {
+			Default:     "",
+			Description: "File the rendered statements are appended to in dry run, they are logged if empty.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigErrorsDeadletterTable: {
+			Default:     "",
+			Description: "Fully qualified name (keyspace.table) of the dead-letter table, required if any error action is deadletter.\nThe table is created if it doesn't exist.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigErrorsLwt: {
+			Default:     "skip",
+			Description: "Action taken when a lightweight transaction is not applied (row already exists on insert, or doesn't exist on\nupdate), one of: fail, skip, deadletter. Writes don't use lightweight transactions with checkpoint.table, fanout\ntables or the debezium input format.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"fail", "skip", "deadletter"}},
+			},
+		},
+		DestinationConfigErrorsMarshal: {
+			Default:     "fail",
+			Description: "Action taken when a record can't be marshalled into a CQL query, one of: fail, skip, deadletter.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"fail", "skip", "deadletter"}},
+			},
+		},
+		DestinationConfigErrorsTimeout: {
+			Default:     "fail",
+			Description: "Action taken when writing a record times out, one of: fail, skip, deadletter.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"fail", "skip", "deadletter"}},
+			},
+		},
+		DestinationConfigErrorsUndefinedColumn: {
+			Default:     "fail",
+			Description: "Action taken when a record contains a column that is not defined in the table, one of: fail, skip, deadletter.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"fail", "skip", "deadletter"}},
+			},
+		},
+		DestinationConfigFanoutColumns: {
+			Default:     "",
+			Description: "Comma separated list of column=field pairs mapping the columns of the table to fields of the record, ex:\nemail=payload_email,id. Fields are looked up in the key, then in the payload, nested fields are separated by\ndots. A column without a field is mapped to the field with the same name. If empty, all the fields of the key\nand the payload are written to the columns with the same name.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigFanoutKey: {
+			Default:     "",
+			Description: "Comma separated list of the primary key columns of the table, used to delete rows.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigGenerators: {
+			Default:     "",
+			Description: "Comma separated list of column=generator pairs, used to fill the columns missing from a record, ex:\nid=now(),created_at=toTimestamp(now()). Supported generators are now() (timeuuid), uuid(), toTimestamp(now())\nand hash(field1|field2) (hex encoded SHA-256 hash of the fields). Only hash can be used with checkpoint.table.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigHostAllowList: {
+			Default:     "",
+			Description: "Comma separated list of hosts the connector is allowed to connect to, other hosts discovered in the cluster are\nignored, ex: 127.0.0.1,127.0.0.2:9042. A host with a port is only allowed on that port. All hosts are allowed if\nempty.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigHostSelection: {
+			Default:     "roundRobin",
+			Description: "Policy used to select the host that coordinates a query, one of: roundRobin, dcAware, rackAware, tokenAware.",
 			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{
-				config.ValidationInclusion{List: []string{"none", "basic"}},
+				config.ValidationInclusion{List: []string{"roundRobin", "dcAware", "rackAware", "tokenAware"}},
+			},
+		},
+		DestinationConfigInputFormat: {
+			Default:     "opencdc",
+			Description: "Format of the records, one of: opencdc (records are written as is), debezium (the payload is a Debezium change\nevent, its before and after fields are unwrapped, its op field is the operation of the record and its\nsource.ts_us, source.ts_ns or source.ts_ms field is the write timestamp).",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"opencdc", "debezium"}},
 			},
 		},
 		DestinationConfigKeyspace: {
 			Default:     "",
-			Description: "The keyspace name that has the table (similar to a database in a relational database system).",
+			Description: "The keyspace name that has the table (similar to a database in a relational database system).\nIt can contain a Go template that is executed for each record to determine the keyspace, ex: `tenant_{{ .Key.tenant_id }}`.",
 			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{
 				config.ValidationRequired{},
 			},
 		},
+		DestinationConfigLocalDC: {
+			Default:     "",
+			Description: "Name of the local datacenter, required by the dcAware and rackAware host selection policies. With the tokenAware\npolicy, it makes the fallback policy prefer hosts in the local datacenter.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigLocalRack: {
+			Default:     "",
+			Description: "Name of the local rack, required by the rackAware host selection policy.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigMetricsAddress: {
+			Default:     "",
+			Description: "Address the HTTP server exposing the metrics in the Prometheus format listens on, ex: :9464. Metrics are served\nat the /metrics path, the server is disabled if empty.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigMode: {
+			Default:     "mirror",
+			Description: "How records are written, one of: mirror (records are applied to the table, so it mirrors the source), changelog\n(each record is appended as an event to the table, which is created if it doesn't exist).",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"mirror", "changelog"}},
+			},
+		},
 		DestinationConfigNodes: {
 			Default:     "",
-			Description: "Comma separated list of Cassandra nodes' addresses (at least one), ex: 127.0.0.1:9042,127.0.0.2:8080",
+			Description: "Comma separated list of Cassandra nodes' addresses (at least one), ex: 127.0.0.1:9042,127.0.0.2:8080\nNodes can also be discovered using DNS, either from an SRV record, ex: srv://_cql._tcp.cassandra.local, or from\nall the addresses of a hostname, ex: dns://cassandra-headless:9042",
 			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{
 				config.ValidationRequired{},
 			},
 		},
+		DestinationConfigNullValues: {
+			Default:     "null",
+			Description: "How payload fields with a null value are written, one of: null (the column is set to null), unset (the column\nis left unchanged, requires the protocol version 4), delete (the column is deleted with a DELETE statement on\nupdates, and left unchanged on inserts).",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"null", "unset", "delete"}},
+			},
+		},
+		DestinationConfigOtelEndpoint: {
+			Default:     "",
+			Description: "Endpoint (host:port) of the OTLP collector, the OTEL_EXPORTER_OTLP_ENDPOINT environment variable or\nlocalhost:4317 is used if empty.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigOtelExporter: {
+			Default:     "none",
+			Description: "Exporter of the OpenTelemetry spans created around writes, batches and statements, one of: none (spans are not\ncreated), otlp (spans are exported to an OTLP collector over gRPC).",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{
+				config.ValidationInclusion{List: []string{"none", "otlp"}},
+			},
+		},
+		DestinationConfigOtelInsecure: {
+			Default:     "false",
+			Description: "Whether to disable TLS when connecting to the OTLP collector.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigQueryDelete: {
+			Default:     "",
+			Description: "CQL statement executed for delete records instead of the generated delete, with the same placeholders as\nquery.insert.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigQueryIdempotent: {
+			Default:     "false",
+			Description: "Whether the query templates are idempotent, so they are retried when they fail. Templates are not retried by\ndefault, since retrying a statement like a counter increment applies it twice.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigQueryInsert: {
+			Default:     "",
+			Description: "CQL statement executed for create and snapshot records instead of the generated insert, its named placeholders\nare bound to fields of the record, ex: INSERT INTO ks.users (id, name) VALUES (:key.id, :payload.name).",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigQueryUpdate: {
+			Default:     "",
+			Description: "CQL statement executed for update records instead of the generated update, with the same placeholders as\nquery.insert.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigRateLimitAdaptive: {
+			Default:     "false",
+			Description: "Whether to halve the records rate when the cluster times out or is overloaded, and to slowly increase it back\nto rateLimit.records once the cluster recovers. Only the records rate is adapted, not the concurrency or the\nbatch size, which are not controlled by the connector. Requires rateLimit.records.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigRateLimitAdaptiveMinRecords: {
+			Default:     "1",
+			Description: "Minimum records rate of the adaptive rate limit.",
+			Type:        config.ParameterTypeFloat,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigRateLimitBytes: {
+			Default:     "0",
+			Description: "Maximum number of bytes (key and payload) written per second, the rate is unlimited if zero.",
+			Type:        config.ParameterTypeInt,
+			Validations: []config.Validation{
+				config.ValidationGreaterThan{V: -1},
+			},
+		},
+		DestinationConfigRateLimitRecords: {
+			Default:     "0",
+			Description: "Maximum number of records written per second, the rate is unlimited if zero.",
+			Type:        config.ParameterTypeFloat,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigRetryDowngradingConsistency: {
+			Default:     "",
+			Description: "Comma separated list of consistency levels used for each retry, ex: QUORUM,ONE. If set, retries downgrade the\nconsistency level instead of using an exponential backoff.",
+			Type:        config.ParameterTypeString,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigRetryMaxBackoff: {
+			Default:     "10s",
+			Description: "Maximum time to wait before retrying a query.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigRetryMaxRetries: {
+			Default:     "3",
+			Description: "Maximum number of times a query that failed with a transient error is retried, retries are disabled if set to 0.\nOnly idempotent queries are retried, lightweight transactions are never retried.",
+			Type:        config.ParameterTypeInt,
+			Validations: []config.Validation{
+				config.ValidationGreaterThan{V: -1},
+			},
+		},
+		DestinationConfigRetryMinBackoff: {
+			Default:     "100ms",
+			Description: "Minimum time to wait before retrying a query, the wait time grows exponentially with each retry.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigSchemaAutoCreate: {
+			Default:     "false",
+			Description: "Whether to create the table from the record schemas if it doesn't exist, the key fields are the partition key\nand the payload fields are the other columns. Requires schema.enabled.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigSchemaEnabled: {
+			Default:     "false",
+			Description: "Whether to use the schemas referenced by the opencdc.key.schema.* and opencdc.payload.schema.* metadata of the\nrecords to convert the values into the types of the schema fields.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigSchemaEvolve: {
+			Default:     "false",
+			Description: "Whether to add a column to the table for each payload field of the record schema that has no column. Requires\nschema.enabled.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigSchemaValidate: {
+			Default:     "false",
+			Description: "Whether to validate the table against the record schemas, the key fields should be primary key columns, and\nthe payload fields should be columns with a compatible type. Requires schema.enabled.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigShuffleReplicas: {
+			Default:     "false",
+			Description: "Whether to shuffle the replicas picked by the tokenAware host selection policy, spreading the load between them.",
+			Type:        config.ParameterTypeBool,
+			Validations: []config.Validation{},
+		},
+		DestinationConfigSlowQueryThreshold: {
+			Default:     "",
+			Description: "Statements taking longer than this threshold are logged with their coordinator, attempt and consistency level,\nslow statements are not logged if zero.",
+			Type:        config.ParameterTypeDuration,
+			Validations: []config.Validation{},
+		},
 		DestinationConfigTable: {
 			Default:     "",
-			Description: "The table name.",
+			Description: "The table name. It can contain a Go template that is executed for each record to determine the table,\nex: `{{ index .Metadata \"opencdc.collection\" }}_v2`.",
 			Type:        config.ParameterTypeString,
 			Validations: []config.Validation{
 				config.ValidationRequired{},
 			},
 		},
+		DestinationConfigTracingSampleRate: {
+			Default:     "0",
+			Description: "Rate of the statements traced by Cassandra, between 0 (no statement) and 1 (all the statements). The traces are\nread from the system_traces keyspace and logged.",
+			Type:        config.ParameterTypeFloat,
+			Validations: []config.Validation{},
+		},
 	}
 }
@@ -15,49 +15,429 @@
 package cassandra
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
 )
 
 const (
-	insertQuery = "INSERT INTO %s (%s) VALUES (%s) IF NOT EXISTS"
-	updateQuery = "UPDATE %s SET %s WHERE %s IF EXISTS"
-	deleteQuery = "DELETE FROM %s WHERE %s"
+	insertQuery        = "INSERT INTO %s (%s) VALUES (%s)"
+	updateQuery        = "UPDATE %s SET %s WHERE %s"
+	deleteQuery        = "DELETE FROM %s WHERE %s"
+	deleteColumnsQuery = "DELETE %s FROM %s WHERE %s"
+
+	// conditions of the lightweight transactions used to detect conflicts
+	ifNotExistsCondition = " IF NOT EXISTS"
+	ifExistsCondition    = " IF EXISTS"
+
+	selectJSONQuery = "SELECT JSON * FROM %s WHERE %s"
+	insertJSONQuery = "INSERT INTO %s JSON ? DEFAULT UNSET USING TTL ?"
 
 	setStatementSeparator   = ","
 	whereStatementSeparator = "AND"
+
+	metadataCassandraDeleteRange   = "cassandra.delete.range"
+	metadataCassandraDeleteColumns = "cassandra.delete.columns"
 )
 
+const (
+	// NullValuesNull binds null to the columns of null fields, which deletes their value.
+	NullValuesNull = "null"
+	// NullValuesUnset binds the unset value to the columns of null fields, which leaves their value unchanged.
+	NullValuesUnset = "unset"
+	// NullValuesDelete deletes the columns of null fields with a separate DELETE statement.
+	NullValuesDelete = "delete"
+)
+
+// rangeOperators maps the bounds of the cassandra.delete.range metadata to CQL operators.
+var rangeOperators = map[string]string{
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+}
+
+// Statement is a CQL statement built from a record.
+type Statement struct {
+	// CQL is the query string.
+	CQL string
+	// Values are the values bound to the query placeholders.
+	Values []interface{}
+	// Columns are the names of the columns bound to the query placeholders, in the same order as Values.
+	Columns []string
+	// Operation is the operation of the record the statement was built from.
+	Operation opencdc.Operation
+	// NonIdempotent is true if the statement can't be safely retried, ex: a query template incrementing a counter.
+	NonIdempotent bool
+}
+
 // QueryBuilder builds a CQL query statement and its values from a record.
-type QueryBuilder struct{}
+type QueryBuilder struct {
+	// tables returns the metadata of a table, used to order the columns as they are defined in the table schema. If
+	// it's nil or the table is unknown, the columns are sorted lexicographically.
+	tables tableMetadataFn
+	// nullValues is how payload fields with a null value are written, one of the NullValues constants. Defaults to
+	// NullValuesNull if empty.
+	nullValues string
+	// unconditional removes the lightweight transaction conditions from inserts and updates, so they are upserts that
+	// can be executed in a batch with statements on other tables.
+	unconditional bool
+}
+
+// BuildQuery takes a record, and returns the statement representing that record based on its operation.
+func (q *QueryBuilder) BuildQuery(rec opencdc.Record, table string) (Statement, error) {
+	switch rec.Operation {
+	case opencdc.OperationCreate, opencdc.OperationSnapshot:
+		return q.BuildInsertQuery(rec, table), nil
+	case opencdc.OperationUpdate:
+		return q.BuildUpdateQuery(rec, table), nil
+	case opencdc.OperationDelete:
+		return q.BuildDeleteQuery(rec, table)
+	default:
+		return Statement{}, fmt.Errorf("unsupported operation %q", rec.Operation)
+	}
+}
 
-// BuildInsertQuery takes a record, and returns the insert query statement and values representing that record.
-func (q *QueryBuilder) BuildInsertQuery(rec opencdc.Record, table string) (string, []interface{}) {
-	keyCols, keyVals, cols, vals := q.getColumnsAndValues(rec.Key.(opencdc.StructuredData), rec.Payload.After.(opencdc.StructuredData))
+// BuildInsertQuery takes a record, and returns the insert statement representing that record.
+func (q *QueryBuilder) BuildInsertQuery(rec opencdc.Record, table string) Statement {
+	keyCols, keyVals, cols, vals := q.getColumnsAndValues(table, rec.Key.(opencdc.StructuredData), rec.Payload.After.(opencdc.StructuredData))
 	cols = append(cols, keyCols...)
 	vals = append(vals, keyVals...)
-	query := fmt.Sprintf(insertQuery, table, strings.Join(cols, ", "), q.getPlaceholders(len(cols)))
-	return query, vals
+	cql := fmt.Sprintf(insertQuery, table, strings.Join(cols, ", "), q.getValuePlaceholders(vals)) + q.condition(ifNotExistsCondition)
+	cols, vals = q.boundValues(cols, vals)
+	return Statement{
+		CQL:       cql,
+		Values:    vals,
+		Columns:   cols,
+		Operation: rec.Operation,
+	}
 }
 
-// BuildUpdateQuery takes a record, and returns the update query statement and values representing that record.
-func (q *QueryBuilder) BuildUpdateQuery(rec opencdc.Record, table string) (string, []interface{}) {
-	keyCols, keyVals, cols, vals := q.getColumnsAndValues(rec.Key.(opencdc.StructuredData), rec.Payload.After.(opencdc.StructuredData))
-	setStatement := q.pairValuesWithPlaceholder(cols, setStatementSeparator)
+// BuildUpdateQuery takes a record, and returns the update statement representing that record.
+func (q *QueryBuilder) BuildUpdateQuery(rec opencdc.Record, table string) Statement {
+	keyCols, keyVals, cols, vals := q.getColumnsAndValues(table, rec.Key.(opencdc.StructuredData), rec.Payload.After.(opencdc.StructuredData))
+	setStatement := q.pairColumnsWithValues(cols, vals, setStatementSeparator)
+	cols, vals = q.boundValues(cols, vals)
 	whereStatement := q.pairValuesWithPlaceholder(keyCols, whereStatementSeparator)
-	vals = append(vals, keyVals...)
-	query := fmt.Sprintf(updateQuery, table, setStatement, whereStatement)
-	return query, vals
+	return Statement{
+		CQL:       fmt.Sprintf(updateQuery, table, setStatement, whereStatement) + q.condition(ifExistsCondition),
+		Values:    append(vals, keyVals...),
+		Columns:   append(cols, keyCols...),
+		Operation: rec.Operation,
+	}
+}
+
+// BuildUpdateStatements takes an update record, and returns the statements representing that record. It's the update
+// statement, followed by the statement deleting the columns of null fields if nullValues is NullValuesDelete. The
+// update statement is omitted if all the payload fields are null.
+func (q *QueryBuilder) BuildUpdateStatements(rec opencdc.Record, table string) []Statement {
+	stmt := q.BuildUpdateQuery(rec, table)
+	if q.nullValues != NullValuesDelete {
+		return []Statement{stmt}
+	}
+	nullColumns := q.nullColumns(table, rec.Key.(opencdc.StructuredData), rec.Payload.After.(opencdc.StructuredData))
+	if len(nullColumns) == 0 {
+		return []Statement{stmt}
+	}
+	keyCols, keyVals, _, _ := q.getColumnsAndValues(table, rec.Key.(opencdc.StructuredData), nil)
+	deleteStmt := Statement{
+		CQL:       fmt.Sprintf(deleteColumnsQuery, strings.Join(nullColumns, ", "), table, q.pairValuesWithPlaceholder(keyCols, whereStatementSeparator)) + q.condition(ifExistsCondition),
+		Values:    keyVals,
+		Columns:   keyCols,
+		Operation: rec.Operation,
+	}
+	if len(stmt.Columns) == len(keyCols) {
+		// all the payload fields are null, there is nothing to set
+		return []Statement{deleteStmt}
+	}
+	return []Statement{stmt, deleteStmt}
+}
+
+// nullColumns returns the payload fields that are explicitly set to null, excluding the key fields.
+func (q *QueryBuilder) nullColumns(table string, key, payload opencdc.StructuredData) []string {
+	var columns []string
+	for _, k := range q.orderColumns(table, payload) {
+		if _, ok := key[k]; ok || payload[k] != nil {
+			continue
+		}
+		columns = append(columns, k)
+	}
+	return columns
 }
 
-// BuildDeleteQuery takes a record, and returns the delete query statement and values representing that record.
-func (q *QueryBuilder) BuildDeleteQuery(rec opencdc.Record, table string) (string, []interface{}) {
-	keyCols, keyVals, _, _ := q.getColumnsAndValues(rec.Key.(opencdc.StructuredData), nil)
+// BuildDeleteQuery takes a record, and returns the delete statement representing that record. If the table schema is
+// known, the key is validated against the table primary key: it should contain all the partition key columns, and
+// optionally a prefix of the clustering columns. If it can't be read, the key is used as is, unless the delete requires
+// the schema. A key that contains only part of the primary key deletes the whole partition, or the rows with that
+// clustering prefix. A range of rows can be deleted using the cassandra.delete.range metadata, check buildDeleteRange.
+// Only some columns of the rows are deleted if the record contains the cassandra.delete.columns metadata, a comma
+// separated list of columns.
+func (q *QueryBuilder) BuildDeleteQuery(rec opencdc.Record, table string) (Statement, error) {
+	keyCols, keyVals, _, _ := q.getColumnsAndValues(table, rec.Key.(opencdc.StructuredData), nil)
+
+	var meta *gocql.TableMetadata
+	if q.tables != nil {
+		var err error
+		meta, err = q.tables(table)
+		switch {
+		case err == nil:
+			err = q.validateDeleteKey(meta, keyCols)
+			if err != nil {
+				return Statement{}, err
+			}
+		case q.needsDeleteSchema(rec):
+			return Statement{}, err
+		default:
+			// the key is assumed to be the full primary key, the delete is executed without validating it
+			meta = nil
+		}
+	}
+
+	whereStatement := q.pairValuesWithPlaceholder(keyCols, whereStatementSeparator)
+	if rawColumns, ok := rec.Metadata[metadataCassandraDeleteColumns]; ok {
+		if _, ok := rec.Metadata[metadataCassandraDeleteRange]; ok {
+			return Statement{}, fmt.Errorf("%w: %s and %s metadata can't be used together", errInvalidRecord, metadataCassandraDeleteColumns, metadataCassandraDeleteRange)
+		}
+		columns, err := q.parseDeleteColumns(meta, rawColumns)
+		if err != nil {
+			return Statement{}, err
+		}
+		return Statement{
+			CQL:       fmt.Sprintf(deleteColumnsQuery, strings.Join(columns, ", "), table, whereStatement),
+			Values:    keyVals,
+			Columns:   keyCols,
+			Operation: rec.Operation,
+		}, nil
+	}
+	if rawRange, ok := rec.Metadata[metadataCassandraDeleteRange]; ok {
+		if meta == nil {
+			return Statement{}, fmt.Errorf("%w: range deletes require the table schema", errInvalidRecord)
+		}
+		rangeStatement, rangeCols, rangeVals, err := q.buildDeleteRange(meta, keyCols, rawRange)
+		if err != nil {
+			return Statement{}, err
+		}
+		whereStatement += " " + whereStatementSeparator + " " + rangeStatement
+		keyCols = append(keyCols, rangeCols...)
+		keyVals = append(keyVals, rangeVals...)
+	}
+
+	return Statement{
+		CQL:       fmt.Sprintf(deleteQuery, table, whereStatement),
+		Values:    keyVals,
+		Columns:   keyCols,
+		Operation: rec.Operation,
+	}, nil
+}
+
+// BuildSoftDeleteQuery takes a delete record, and returns the update statement that marks the row as deleted by
+// setting the given columns. The key should identify a single row, range deletes are not supported.
+func (q *QueryBuilder) BuildSoftDeleteQuery(rec opencdc.Record, table string, columns opencdc.StructuredData) (Statement, error) {
+	key := rec.Key.(opencdc.StructuredData)
+	err := q.validateRowKey(rec, table, key)
+	if err != nil {
+		return Statement{}, err
+	}
+	keyCols, keyVals, cols, vals := q.getColumnsAndValues(table, key, columns)
+	setStatement := q.pairValuesWithPlaceholder(cols, setStatementSeparator)
 	whereStatement := q.pairValuesWithPlaceholder(keyCols, whereStatementSeparator)
-	query := fmt.Sprintf(deleteQuery, table, whereStatement)
-	return query, keyVals
+	return Statement{
+		CQL:       fmt.Sprintf(updateQuery, table, setStatement, whereStatement) + q.condition(ifExistsCondition),
+		Values:    append(vals, keyVals...),
+		Columns:   append(cols, keyCols...),
+		Operation: rec.Operation,
+	}, nil
+}
+
+// BuildSelectJSONQuery takes a record, and returns the statement that selects the row identified by its key as JSON.
+// The key should identify a single row.
+func (q *QueryBuilder) BuildSelectJSONQuery(rec opencdc.Record, table string) (Statement, error) {
+	key := rec.Key.(opencdc.StructuredData)
+	err := q.validateRowKey(rec, table, key)
+	if err != nil {
+		return Statement{}, err
+	}
+	keyCols, keyVals, _, _ := q.getColumnsAndValues(table, key, nil)
+	return Statement{
+		CQL:       fmt.Sprintf(selectJSONQuery, table, q.pairValuesWithPlaceholder(keyCols, whereStatementSeparator)),
+		Values:    keyVals,
+		Columns:   keyCols,
+		Operation: rec.Operation,
+	}, nil
+}
+
+// BuildInsertJSONQuery returns the statement that writes a row, as returned by a SELECT JSON query, with the given
+// TTL (truncated to seconds). Null columns are left unset, so they don't create tombstones.
+func (q *QueryBuilder) BuildInsertJSONQuery(rec opencdc.Record, table, row string, ttl time.Duration) Statement {
+	return Statement{
+		CQL:       fmt.Sprintf(insertJSONQuery, table),
+		Values:    []interface{}{row, int(ttl / time.Second)},
+		Columns:   []string{"[json]", "[ttl]"},
+		Operation: rec.Operation,
+	}
+}
+
+// validateRowKey returns an error if the key doesn't identify a single row: if the table schema is known, it should
+// contain all the primary key columns, and the record shouldn't contain a delete range.
+func (q *QueryBuilder) validateRowKey(rec opencdc.Record, table string, key opencdc.StructuredData) error {
+	if _, ok := rec.Metadata[metadataCassandraDeleteRange]; ok {
+		return fmt.Errorf("%w: %s metadata is only supported by hard deletes", errInvalidRecord, metadataCassandraDeleteRange)
+	}
+	if q.tables == nil {
+		return nil
+	}
+	meta, err := q.tables(table)
+	if err != nil {
+		return err
+	}
+	keyCols := q.orderColumns(table, key)
+	err = q.validateDeleteKey(meta, keyCols)
+	if err != nil {
+		return err
+	}
+	if len(keyCols) != len(meta.PartitionKey)+len(meta.ClusteringColumns) {
+		return fmt.Errorf("%w: key should contain all the primary key columns", errInvalidRecord)
+	}
+	return nil
+}
+
+// needsDeleteSchema returns true if the delete of the record can't be built without the table schema, range and column
+// deletes are validated against the schema.
+func (q *QueryBuilder) needsDeleteSchema(rec opencdc.Record) bool {
+	_, hasRange := rec.Metadata[metadataCassandraDeleteRange]
+	_, hasColumns := rec.Metadata[metadataCassandraDeleteColumns]
+	return hasRange || hasColumns
+}
+
+// parseDeleteColumns parses the cassandra.delete.columns metadata, a comma separated list of columns. If the table
+// schema is known, primary key columns are rejected since they can't be deleted.
+func (q *QueryBuilder) parseDeleteColumns(meta *gocql.TableMetadata, rawColumns string) ([]string, error) {
+	var columns []string
+	for _, c := range strings.Split(rawColumns, ",") {
+		c = strings.TrimSpace(c)
+		if !cqlIdentifierRegex.MatchString(c) {
+			return nil, fmt.Errorf("%w: invalid column %q in %s metadata", errInvalidRecord, c, metadataCassandraDeleteColumns)
+		}
+		if meta != nil {
+			if q.isPartitionKeyColumn(meta, c) || q.isClusteringColumn(meta, c) {
+				return nil, fmt.Errorf("%w: primary key column %q can't be deleted", errInvalidRecord, c)
+			}
+		}
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
+
+// validateDeleteKey returns an error if the key columns can't be used to delete rows from the table: all the partition
+// key columns are required, and the clustering columns, if any, should be a prefix of the table clustering columns.
+func (q *QueryBuilder) validateDeleteKey(meta *gocql.TableMetadata, keyCols []string) error {
+	inKey := make(map[string]bool, len(keyCols))
+	for _, c := range keyCols {
+		inKey[c] = true
+	}
+	for _, c := range meta.PartitionKey {
+		if !inKey[c.Name] {
+			return fmt.Errorf("%w: key is missing the partition key column %q", errInvalidRecord, c.Name)
+		}
+		delete(inKey, c.Name)
+	}
+	for _, c := range meta.ClusteringColumns {
+		if !inKey[c.Name] {
+			break
+		}
+		delete(inKey, c.Name)
+	}
+	// remaining columns are either not part of the primary key, or clustering columns that don't form a prefix
+	for _, c := range keyCols {
+		if !inKey[c] {
+			continue
+		}
+		if q.isClusteringColumn(meta, c) {
+			return fmt.Errorf("%w: clustering columns in the key should be a prefix of the table clustering columns, got %q", errInvalidRecord, c)
+		}
+		return fmt.Errorf("%w: key column %q is not part of the primary key", errInvalidRecord, c)
+	}
+	return nil
+}
+
+func (q *QueryBuilder) isPartitionKeyColumn(meta *gocql.TableMetadata, column string) bool {
+	for _, c := range meta.PartitionKey {
+		if c.Name == column {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *QueryBuilder) isClusteringColumn(meta *gocql.TableMetadata, column string) bool {
+	for _, c := range meta.ClusteringColumns {
+		if c.Name == column {
+			return true
+		}
+	}
+	return false
+}
+
+// buildDeleteRange parses the cassandra.delete.range metadata and returns the range conditions on the clustering
+// column that follows the clustering columns in the key, with their columns and values. The metadata is a JSON
+// object containing the column and at least one bound, ex: {"column": "ts", "gte": "2024-01-01T00:00:00Z", "lt":
+// "2024-02-01T00:00:00Z"}. Supported bounds are gt, gte, lt and lte.
+func (q *QueryBuilder) buildDeleteRange(meta *gocql.TableMetadata, keyCols []string, rawRange string) (string, []string, []interface{}, error) {
+	var bounds map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(rawRange))
+	dec.UseNumber()
+	if err := dec.Decode(&bounds); err != nil {
+		return "", nil, nil, fmt.Errorf("%w: invalid %s metadata: %w", errInvalidRecord, metadataCassandraDeleteRange, err)
+	}
+	column, _ := bounds["column"].(string)
+	delete(bounds, "column")
+	if column == "" || len(bounds) == 0 {
+		return "", nil, nil, fmt.Errorf("%w: %s metadata should contain a column and at least one bound", errInvalidRecord, metadataCassandraDeleteRange)
+	}
+
+	// the range is allowed only on the clustering column that follows the ones restricted by the key
+	clusteringInKey := len(keyCols) - len(meta.PartitionKey)
+	if clusteringInKey >= len(meta.ClusteringColumns) || meta.ClusteringColumns[clusteringInKey].Name != column {
+		return "", nil, nil, fmt.Errorf("%w: range column %q should be the clustering column that follows the clustering columns in the key", errInvalidRecord, column)
+	}
+	colType := meta.ClusteringColumns[clusteringInKey].Type
+
+	// sort the bounds so the query is deterministic
+	ops := make([]string, 0, len(bounds))
+	for op := range bounds {
+		if _, ok := rangeOperators[op]; !ok {
+			return "", nil, nil, fmt.Errorf("%w: unknown bound %q in %s metadata", errInvalidRecord, op, metadataCassandraDeleteRange)
+		}
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	conditions := make([]string, len(ops))
+	cols := make([]string, len(ops))
+	vals := make([]interface{}, len(ops))
+	for i, op := range ops {
+		val, err := convertValue(bounds[op], colType)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("%w: invalid %s bound: %w", errInvalidRecord, op, err)
+		}
+		conditions[i] = fmt.Sprintf("%s %s ?", column, rangeOperators[op])
+		cols[i] = column
+		vals[i] = val
+	}
+	return strings.Join(conditions, " "+whereStatementSeparator+" "), cols, vals, nil
+}
+
+// condition returns the lightweight transaction condition, or an empty string if the builder is unconditional.
+func (q *QueryBuilder) condition(cond string) string {
+	if q.unconditional {
+		return ""
+	}
+	return cond
 }
 
 // getPlaceholders returns a string of question marks seperated by a comma with a given length.
@@ -65,6 +445,47 @@ func (q *QueryBuilder) getPlaceholders(length int) string {
 	return strings.TrimSuffix(strings.Repeat("?, ", length), ", ")
 }
 
+// getValuePlaceholders returns the placeholders of the values seperated by a comma, values generated by a CQL function
+// are rendered as the function.
+func (q *QueryBuilder) getValuePlaceholders(vals []interface{}) string {
+	placeholders := make([]string, len(vals))
+	for i, v := range vals {
+		placeholders[i] = q.placeholder(v)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// pairColumnsWithValues pairs each column with the placeholder of its value, values generated by a CQL function are
+// rendered as the function.
+func (q *QueryBuilder) pairColumnsWithValues(cols []string, vals []interface{}, separator string) string {
+	pairs := make([]string, len(cols))
+	for i, c := range cols {
+		pairs[i] = c + " = " + q.placeholder(vals[i])
+	}
+	return strings.Join(pairs, " "+separator+" ")
+}
+
+func (q *QueryBuilder) placeholder(value interface{}) string {
+	if f, ok := value.(cqlFunction); ok {
+		return string(f)
+	}
+	return "?"
+}
+
+// boundValues returns the columns and values bound to placeholders, without the values generated by CQL functions.
+func (q *QueryBuilder) boundValues(cols []string, vals []interface{}) ([]string, []interface{}) {
+	boundCols := make([]string, 0, len(cols))
+	boundVals := make([]interface{}, 0, len(vals))
+	for i, v := range vals {
+		if _, ok := v.(cqlFunction); ok {
+			continue
+		}
+		boundCols = append(boundCols, cols[i])
+		boundVals = append(boundVals, v)
+	}
+	return boundCols, boundVals
+}
+
 func (q *QueryBuilder) pairValuesWithPlaceholder(cols []string, separator string) string {
 	if len(cols) == 0 {
 		return ""
@@ -72,27 +493,69 @@ func (q *QueryBuilder) pairValuesWithPlaceholder(cols []string, separator string
 	return strings.Join(cols, " = ? "+separator+" ") + " = ?"
 }
 
-// getColumnsAndValues returns the key columns and values, and the payload columns and values, each in a slice and in the order mentioned.
-func (q *QueryBuilder) getColumnsAndValues(key, payload opencdc.StructuredData) ([]string, []interface{}, []string, []interface{}) {
-	keyColumns := make([]string, 0, len(key))
+// getColumnsAndValues returns the key columns and values, and the payload columns and values, each in a slice and in
+// the order mentioned. Columns are ordered as they are defined in the table schema.
+func (q *QueryBuilder) getColumnsAndValues(table string, key, payload opencdc.StructuredData) ([]string, []interface{}, []string, []interface{}) {
+	keyColumns := q.orderColumns(table, key)
 	keyValues := make([]interface{}, 0, len(key))
-	columns := make([]string, 0, len(payload))
-	values := make([]interface{}, 0, len(payload))
-
-	// range over both the key and payload values
-	for k, v := range key {
-		keyColumns = append(keyColumns, k)
-		keyValues = append(keyValues, v)
+	for _, k := range keyColumns {
+		keyValues = append(keyValues, key[k])
 	}
 
-	for k, v := range payload {
+	columns := make([]string, 0, len(payload))
+	values := make([]interface{}, 0, len(payload))
+	for _, k := range q.orderColumns(table, payload) {
 		// skip Key from payload if exists
 		if _, ok := key[k]; ok {
 			continue
 		}
+		value := payload[k]
+		if value == nil {
+			switch q.nullValues {
+			case NullValuesUnset:
+				value = gocql.UnsetValue
+			case NullValuesDelete:
+				// deleted with a separate statement, check BuildUpdateStatements
+				continue
+			}
+		}
 		columns = append(columns, k)
-		values = append(values, v)
+		values = append(values, value)
 	}
 
 	return keyColumns, keyValues, columns, values
 }
+
+// orderColumns returns the fields of data ordered as the columns in the table schema, fields that are not in the
+// schema (or all fields if the schema is unknown) come last and are sorted lexicographically.
+func (q *QueryBuilder) orderColumns(table string, data opencdc.StructuredData) []string {
+	fields := make([]string, 0, len(data))
+	for k := range data {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+
+	if q.tables == nil {
+		return fields
+	}
+	meta, err := q.tables(table)
+	if err != nil {
+		return fields
+	}
+	position := make(map[string]int, len(meta.Columns))
+	for i, c := range schemaColumnOrder(meta) {
+		position[c] = i
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		pi, iok := position[fields[i]]
+		pj, jok := position[fields[j]]
+		switch {
+		case iok && jok:
+			return pi < pj
+		default:
+			// fields in the schema come first
+			return iok && !jok
+		}
+	})
+	return fields
+}
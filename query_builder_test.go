@@ -15,9 +15,12 @@
 package cassandra
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
 	"github.com/matryer/is"
 )
 
@@ -47,9 +50,10 @@ func TestQueryBuilder_Insert(t *testing.T) {
 			},
 		},
 	}
-	cql, vals := builder.BuildInsertQuery(rec, "my_table")
-	is.Equal(cql, "INSERT INTO my_table (age, id) VALUES (?, ?) IF NOT EXISTS")
-	is.Equal(vals, []interface{}{22, "6"})
+	stmt := builder.BuildInsertQuery(rec, "my_table")
+	is.Equal(stmt.CQL, "INSERT INTO my_table (age, id) VALUES (?, ?) IF NOT EXISTS")
+	is.Equal(stmt.Values, []interface{}{22, "6"})
+	is.Equal(stmt.Columns, []string{"age", "id"})
 }
 
 func TestQueryBuilder_Update(t *testing.T) {
@@ -64,22 +68,260 @@ func TestQueryBuilder_Update(t *testing.T) {
 			},
 		},
 	}
-	cql, vals := builder.BuildUpdateQuery(rec, "my_table")
-	is.Equal(cql, "UPDATE my_table SET age = ? WHERE id = ? IF EXISTS")
-	is.Equal(vals, []interface{}{33, "6"})
+	stmt := builder.BuildUpdateQuery(rec, "my_table")
+	is.Equal(stmt.CQL, "UPDATE my_table SET age = ? WHERE id = ? IF EXISTS")
+	is.Equal(stmt.Values, []interface{}{33, "6"})
 }
 
 func TestQueryBuilder_Delete(t *testing.T) {
 	is := is.New(t)
 	builder := QueryBuilder{}
 	rec := opencdc.Record{
-		Key: opencdc.StructuredData{"id": "6", "id2": "6"},
+		Key: opencdc.StructuredData{"id": "6", "id2": "7"},
 		Payload: opencdc.Change{
 			After: opencdc.StructuredData{},
 		},
 	}
-	cql, vals := builder.BuildDeleteQuery(rec, "my_table")
-	// key is a map, so we don't guarantee the order
-	is.True(cql == "DELETE FROM my_table WHERE id = ? AND id2 = ?" || cql == "DELETE FROM my_table WHERE id2 = ? AND id = ?")
-	is.Equal(vals, []interface{}{"6", "6"})
+	stmt, err := builder.BuildDeleteQuery(rec, "my_table")
+	is.NoErr(err)
+	is.Equal(stmt.CQL, "DELETE FROM my_table WHERE id = ? AND id2 = ?")
+	is.Equal(stmt.Values, []interface{}{"6", "7"})
+}
+
+func TestQueryBuilder_BuildQuery(t *testing.T) {
+	is := is.New(t)
+	builder := QueryBuilder{}
+	rec := opencdc.Record{
+		Operation: opencdc.OperationSnapshot,
+		Key:       opencdc.StructuredData{"id": "6"},
+		Payload: opencdc.Change{
+			After: opencdc.StructuredData{
+				"name":  "john",
+				"age":   22,
+				"email": "john@example.com",
+			},
+		},
+	}
+	stmt, err := builder.BuildQuery(rec, "my_table")
+	is.NoErr(err)
+	is.Equal(stmt, Statement{
+		CQL:       "INSERT INTO my_table (age, email, name, id) VALUES (?, ?, ?, ?) IF NOT EXISTS",
+		Values:    []interface{}{22, "john@example.com", "john", "6"},
+		Columns:   []string{"age", "email", "name", "id"},
+		Operation: opencdc.OperationSnapshot,
+	})
+
+	rec.Operation = opencdc.Operation(0)
+	_, err = builder.BuildQuery(rec, "my_table")
+	is.True(err != nil)
+}
+
+// testTableMetadata returns the metadata of a table with the primary key ((tenant, id), created, seq).
+func testTableMetadata(string) (*gocql.TableMetadata, error) {
+	created := &gocql.ColumnMetadata{Name: "created", Kind: gocql.ColumnClusteringKey, Type: gocql.NewNativeType(4, gocql.TypeTimestamp, "")}
+	seq := &gocql.ColumnMetadata{Name: "seq", Kind: gocql.ColumnClusteringKey, ComponentIndex: 1, Type: gocql.NewNativeType(4, gocql.TypeInt, "")}
+	return &gocql.TableMetadata{
+		PartitionKey:      []*gocql.ColumnMetadata{{Name: "tenant"}, {Name: "id"}},
+		ClusteringColumns: []*gocql.ColumnMetadata{created, seq},
+		Columns: map[string]*gocql.ColumnMetadata{
+			"tenant": {}, "id": {}, "created": created, "seq": seq, "name": {}, "age": {},
+		},
+		OrderedColumns: []string{"age", "created", "id", "name", "seq", "tenant"},
+	}, nil
+}
+
+func TestQueryBuilder_SchemaOrder(t *testing.T) {
+	is := is.New(t)
+	builder := QueryBuilder{tables: testTableMetadata}
+	rec := opencdc.Record{
+		Key: opencdc.StructuredData{"id": "6", "tenant": "acme", "created": 1},
+		Payload: opencdc.Change{
+			After: opencdc.StructuredData{
+				"name":    "john",
+				"age":     22,
+				"unknown": true,
+			},
+		},
+	}
+	stmt := builder.BuildUpdateQuery(rec, "ks.my_table")
+	is.Equal(stmt.CQL, "UPDATE ks.my_table SET age = ? , name = ? , unknown = ? WHERE tenant = ? AND id = ? AND created = ? IF EXISTS")
+	is.Equal(stmt.Columns, []string{"age", "name", "unknown", "tenant", "id", "created"})
+}
+
+func TestQueryBuilder_PartialKeyDelete(t *testing.T) {
+	builder := QueryBuilder{tables: testTableMetadata}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name       string
+		key        opencdc.StructuredData
+		metadata   opencdc.Metadata
+		wantCQL    string
+		wantValues []interface{}
+		wantErr    bool
+	}{{
+		name:       "row delete",
+		key:        opencdc.StructuredData{"tenant": "acme", "id": 1, "created": created, "seq": 2},
+		wantCQL:    "DELETE FROM ks.t WHERE tenant = ? AND id = ? AND created = ? AND seq = ?",
+		wantValues: []interface{}{"acme", 1, created, 2},
+	}, {
+		name:       "partition delete",
+		key:        opencdc.StructuredData{"id": 1, "tenant": "acme"},
+		wantCQL:    "DELETE FROM ks.t WHERE tenant = ? AND id = ?",
+		wantValues: []interface{}{"acme", 1},
+	}, {
+		name:       "clustering prefix delete",
+		key:        opencdc.StructuredData{"tenant": "acme", "id": 1, "created": created},
+		wantCQL:    "DELETE FROM ks.t WHERE tenant = ? AND id = ? AND created = ?",
+		wantValues: []interface{}{"acme", 1, created},
+	}, {
+		name: "range delete",
+		key:  opencdc.StructuredData{"tenant": "acme", "id": 1},
+		metadata: opencdc.Metadata{
+			metadataCassandraDeleteRange: `{"column": "created", "gte": "2024-01-01T00:00:00Z", "lt": 1706745600000}`,
+		},
+		wantCQL:    "DELETE FROM ks.t WHERE tenant = ? AND id = ? AND created >= ? AND created < ?",
+		wantValues: []interface{}{"acme", 1, created, int64(1706745600000)},
+	}, {
+		name: "range delete on second clustering column",
+		key:  opencdc.StructuredData{"tenant": "acme", "id": 1, "created": created},
+		metadata: opencdc.Metadata{
+			metadataCassandraDeleteRange: `{"column": "seq", "gt": 5}`,
+		},
+		wantCQL:    "DELETE FROM ks.t WHERE tenant = ? AND id = ? AND created = ? AND seq > ?",
+		wantValues: []interface{}{"acme", 1, created, int64(5)},
+	}, {
+		name: "range on a column that doesn't follow the key",
+		key:  opencdc.StructuredData{"tenant": "acme", "id": 1},
+		metadata: opencdc.Metadata{
+			metadataCassandraDeleteRange: `{"column": "seq", "gt": 5}`,
+		},
+		wantErr: true,
+	}, {
+		name: "unknown bound",
+		key:  opencdc.StructuredData{"tenant": "acme", "id": 1},
+		metadata: opencdc.Metadata{
+			metadataCassandraDeleteRange: `{"column": "created", "from": 5}`,
+		},
+		wantErr: true,
+	}, {
+		name:    "missing partition key column",
+		key:     opencdc.StructuredData{"tenant": "acme"},
+		wantErr: true,
+	}, {
+		name:    "clustering columns are not a prefix",
+		key:     opencdc.StructuredData{"tenant": "acme", "id": 1, "seq": 2},
+		wantErr: true,
+	}, {
+		name:    "regular column in key",
+		key:     opencdc.StructuredData{"tenant": "acme", "id": 1, "name": "john"},
+		wantErr: true,
+	}, {
+		name:       "column delete",
+		key:        opencdc.StructuredData{"tenant": "acme", "id": 1, "created": created, "seq": 2},
+		metadata:   opencdc.Metadata{metadataCassandraDeleteColumns: "name, age"},
+		wantCQL:    "DELETE name, age FROM ks.t WHERE tenant = ? AND id = ? AND created = ? AND seq = ?",
+		wantValues: []interface{}{"acme", 1, created, 2},
+	}, {
+		name:     "column delete of a primary key column",
+		key:      opencdc.StructuredData{"tenant": "acme", "id": 1, "created": created, "seq": 2},
+		metadata: opencdc.Metadata{metadataCassandraDeleteColumns: "name,seq"},
+		wantErr:  true,
+	}, {
+		name: "column delete with a range",
+		key:  opencdc.StructuredData{"tenant": "acme", "id": 1},
+		metadata: opencdc.Metadata{
+			metadataCassandraDeleteColumns: "name",
+			metadataCassandraDeleteRange:   `{"column": "created", "gt": 5}`,
+		},
+		wantErr: true,
+	},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			stmt, err := builder.BuildDeleteQuery(opencdc.Record{
+				Operation: opencdc.OperationDelete,
+				Key:       tt.key,
+				Metadata:  tt.metadata,
+			}, "ks.t")
+			if tt.wantErr {
+				is.True(errors.Is(err, errInvalidRecord))
+				return
+			}
+			is.NoErr(err)
+			is.Equal(stmt.CQL, tt.wantCQL)
+			is.Equal(stmt.Values, tt.wantValues)
+		})
+	}
+}
+
+func TestQueryBuilder_BuildDeleteQueryUnknownTable(t *testing.T) {
+	is := is.New(t)
+	errUnknownTable := errors.New("unknown table")
+	builder := QueryBuilder{tables: func(string) (*gocql.TableMetadata, error) {
+		return nil, errUnknownTable
+	}}
+	rec := opencdc.Record{
+		Operation: opencdc.OperationDelete,
+		Key:       opencdc.StructuredData{"id": 1},
+	}
+
+	// a row delete doesn't need the table schema
+	stmt, err := builder.BuildDeleteQuery(rec, "ks.t")
+	is.NoErr(err)
+	is.Equal(stmt.CQL, "DELETE FROM ks.t WHERE id = ?")
+
+	rec.Metadata = opencdc.Metadata{metadataCassandraDeleteColumns: "name"}
+	_, err = builder.BuildDeleteQuery(rec, "ks.t")
+	is.True(errors.Is(err, errUnknownTable))
+}
+
+func TestQueryBuilder_NullValues(t *testing.T) {
+	rec := opencdc.Record{
+		Operation: opencdc.OperationUpdate,
+		Key:       opencdc.StructuredData{"id": 1},
+		Payload: opencdc.Change{
+			After: opencdc.StructuredData{"name": "john", "age": nil},
+		},
+	}
+	testCases := []struct {
+		nullValues string
+		wantCQL    []string
+		wantValues [][]interface{}
+	}{{
+		nullValues: NullValuesNull,
+		wantCQL:    []string{"UPDATE ks.t SET age = ? , name = ? WHERE id = ? IF EXISTS"},
+		wantValues: [][]interface{}{{nil, "john", 1}},
+	}, {
+		nullValues: NullValuesUnset,
+		wantCQL:    []string{"UPDATE ks.t SET age = ? , name = ? WHERE id = ? IF EXISTS"},
+		wantValues: [][]interface{}{{gocql.UnsetValue, "john", 1}},
+	}, {
+		nullValues: NullValuesDelete,
+		wantCQL: []string{
+			"UPDATE ks.t SET name = ? WHERE id = ? IF EXISTS",
+			"DELETE age FROM ks.t WHERE id = ? IF EXISTS",
+		},
+		wantValues: [][]interface{}{{"john", 1}, {1}},
+	}}
+	for _, tt := range testCases {
+		t.Run(tt.nullValues, func(t *testing.T) {
+			is := is.New(t)
+			builder := QueryBuilder{nullValues: tt.nullValues}
+			stmts := builder.BuildUpdateStatements(rec, "ks.t")
+			is.Equal(len(stmts), len(tt.wantCQL))
+			for i, stmt := range stmts {
+				is.Equal(stmt.CQL, tt.wantCQL[i])
+				is.Equal(stmt.Values, tt.wantValues[i])
+			}
+		})
+	}
+
+	// only null fields
+	is := is.New(t)
+	builder := QueryBuilder{nullValues: NullValuesDelete}
+	rec.Payload.After = opencdc.StructuredData{"age": nil}
+	stmts := builder.BuildUpdateStatements(rec, "ks.t")
+	is.Equal(len(stmts), 1)
+	is.Equal(stmts[0].CQL, "DELETE age FROM ks.t WHERE id = ? IF EXISTS")
 }
@@ -0,0 +1,250 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+)
+
+const (
+	templateSourceKey     = "key"
+	templateSourcePayload = "payload"
+	templateSourceMeta    = "meta"
+)
+
+var (
+	// templatePlaceholderRegex matches the named placeholders of the query templates, ex: :key.id, :payload.name or
+	// :meta.opencdc.collection.
+	templatePlaceholderRegex = regexp.MustCompile(`:(key|payload|meta)\.([A-Za-z0-9_]+(?:\.[A-Za-z0-9_]+)*)`)
+	templateConditionRegex   = regexp.MustCompile(`(?i)\bIF\b`)
+	templateBatchRegex       = regexp.MustCompile(`(?i)^\s*BEGIN\b`)
+
+	// errTemplatePrepared stops the execution of a query template once it's prepared.
+	errTemplatePrepared = errors.New("query template prepared")
+)
+
+// queryTemplate is a CQL statement configured for an operation, its named placeholders are bound to fields of the
+// record.
+type queryTemplate struct {
+	// name is the name of the parameter configuring the template.
+	name string
+	// cql is the statement with the named placeholders replaced by ? placeholders.
+	cql string
+	// fields are the record fields bound to the placeholders, in order.
+	fields []templateField
+	// types are the types of the placeholders, known once the template is prepared.
+	types []gocql.TypeInfo
+	// conditional is true if the statement is a lightweight transaction.
+	conditional bool
+	// batch is true if the statement is a batch.
+	batch bool
+	// idempotent is true if the statement can be safely retried.
+	idempotent bool
+}
+
+// templateField is a field of the record bound to a placeholder, the path of key and payload fields is split on dots
+// to bind nested fields, the path of metadata fields is the metadata key.
+type templateField struct {
+	source string
+	path   string
+}
+
+// parseQueryTemplates parses the configured query templates by operation, creates and snapshots use the insert
+// template.
+func (d *DestinationConfig) parseQueryTemplates() (map[opencdc.Operation]*queryTemplate, error) {
+	templates := make(map[opencdc.Operation]*queryTemplate)
+	for _, t := range []struct {
+		name       string
+		template   string
+		operations []opencdc.Operation
+	}{
+		{name: "query.insert", template: d.QueryInsert, operations: []opencdc.Operation{opencdc.OperationCreate, opencdc.OperationSnapshot}},
+		{name: "query.update", template: d.QueryUpdate, operations: []opencdc.Operation{opencdc.OperationUpdate}},
+		{name: "query.delete", template: d.QueryDelete, operations: []opencdc.Operation{opencdc.OperationDelete}},
+	} {
+		if t.template == "" {
+			continue
+		}
+		if d.Mode == ModeChangelog {
+			return nil, fmt.Errorf("%s can't be used in changelog mode", t.name)
+		}
+		qt, err := parseQueryTemplate(t.name, t.template)
+		if err != nil {
+			return nil, err
+		}
+		qt.idempotent = d.QueryIdempotent
+		// statements are batched with the checkpoint update
+		if d.checkpointEnabled() && (qt.conditional || qt.batch) {
+			return nil, fmt.Errorf("%s can't be a lightweight transaction or a batch with checkpoint.table", t.name)
+		}
+		// Debezium events are written with their source timestamp, which lightweight transactions can't use
+		if d.InputFormat == InputFormatDebezium && qt.conditional {
+			return nil, fmt.Errorf("%s can't be a lightweight transaction with the %s input format", t.name, InputFormatDebezium)
+		}
+		for _, op := range t.operations {
+			templates[op] = qt
+		}
+	}
+	return templates, nil
+}
+
+// parseQueryTemplate replaces the named placeholders of the template by ? placeholders, placeholders in string
+// literals are left as is.
+func parseQueryTemplate(name, template string) (*queryTemplate, error) {
+	qt := &queryTemplate{name: name, batch: templateBatchRegex.MatchString(template)}
+	var cql, unquoted strings.Builder
+	// the template is split on quotes, so even parts are outside string literals
+	for i, part := range strings.Split(template, "'") {
+		if i > 0 {
+			cql.WriteString("'")
+		}
+		if i%2 == 1 {
+			cql.WriteString(part)
+			continue
+		}
+		unquoted.WriteString(part)
+		cql.WriteString(templatePlaceholderRegex.ReplaceAllStringFunc(part, func(placeholder string) string {
+			m := templatePlaceholderRegex.FindStringSubmatch(placeholder)
+			qt.fields = append(qt.fields, templateField{source: m[1], path: m[2]})
+			return "?"
+		}))
+	}
+	if strings.Count(template, "'")%2 == 1 {
+		return nil, fmt.Errorf("invalid %s: unterminated string literal", name)
+	}
+	if len(qt.fields) == 0 {
+		return nil, fmt.Errorf("invalid %s: should contain at least one placeholder, ex: :key.id", name)
+	}
+	qt.cql = cql.String()
+	qt.conditional = templateConditionRegex.MatchString(unquoted.String())
+	return qt, nil
+}
+
+// prepareQueryTemplates prepares the query templates on the cluster without executing them, so invalid templates
+// fail when the destination is opened. The types of the placeholders are used to convert the bound values.
+func (d *Destination) prepareQueryTemplates() error {
+	prepared := make(map[*queryTemplate]bool)
+	for _, qt := range d.queryTemplates {
+		if prepared[qt] {
+			continue
+		}
+		prepared[qt] = true
+		err := d.session.Bind(qt.cql, func(info *gocql.QueryInfo) ([]interface{}, error) {
+			if len(info.Args) != len(qt.fields) {
+				return nil, fmt.Errorf("the template has %d placeholders, but the prepared statement has %d bind markers",
+					len(qt.fields), len(info.Args))
+			}
+			qt.types = make([]gocql.TypeInfo, len(info.Args))
+			for i, arg := range info.Args {
+				// counter updates can't be executed in a logged batch
+				if d.config.checkpointEnabled() && arg.TypeInfo.Type() == gocql.TypeCounter {
+					return nil, fmt.Errorf("counters are unsupported with checkpoint.table, counter updates can't be batched with the checkpoint update")
+				}
+				qt.types[i] = arg.TypeInfo
+			}
+			return nil, errTemplatePrepared
+		}).Exec()
+		if !errors.Is(err, errTemplatePrepared) {
+			return fmt.Errorf("invalid %s: %w", qt.name, err)
+		}
+	}
+	return nil
+}
+
+// statement returns the statement of the template with the fields of the record bound to its placeholders. Payload
+// fields are taken from the payload after, or from the payload before if there is none, ex: for deletes.
+func (qt *queryTemplate) statement(record opencdc.Record) (Statement, error) {
+	values := make([]interface{}, len(qt.fields))
+	for i, f := range qt.fields {
+		var value interface{}
+		var ok bool
+		switch f.source {
+		case templateSourceKey:
+			value, ok = lookupPath(record.Key, f.path)
+		case templateSourcePayload:
+			payload := record.Payload.After
+			if payload == nil {
+				payload = record.Payload.Before
+			}
+			value, ok = lookupPath(payload, f.path)
+		case templateSourceMeta:
+			value, ok = record.Metadata[f.path]
+		}
+		if !ok {
+			return Statement{}, fmt.Errorf("%w: field %s.%s of %s not found", errInvalidRecord, f.source, f.path, qt.name)
+		}
+		if qt.types != nil {
+			var err error
+			value, err = convertValue(value, qt.types[i])
+			if err != nil {
+				return Statement{}, fmt.Errorf("%w: field %s.%s of %s: %w", errInvalidRecord, f.source, f.path, qt.name, err)
+			}
+		}
+		values[i] = value
+	}
+	return Statement{CQL: qt.cql, Values: values, Operation: record.Operation, NonIdempotent: !qt.idempotent}, nil
+}
+
+// lookupPath returns the value of the nested field of the structured data, the path is split on dots.
+func lookupPath(data opencdc.Data, path string) (interface{}, bool) {
+	var value interface{} = data
+	for _, field := range strings.Split(path, ".") {
+		var m map[string]interface{}
+		switch v := value.(type) {
+		case opencdc.StructuredData:
+			m = v
+		case map[string]interface{}:
+			m = v
+		default:
+			return nil, false
+		}
+		var ok bool
+		if value, ok = m[field]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// execTemplate executes the query template of the record operation. Lightweight transactions fail if they are not
+// applied.
+func (d *Destination) execTemplate(ctx context.Context, record opencdc.Record, table string, qt *queryTemplate) error {
+	stmt, err := qt.statement(record)
+	if err != nil {
+		return err
+	}
+	if !qt.conditional {
+		err = d.exec(ctx, record, table, stmt)
+		if err != nil {
+			return fmt.Errorf("error while executing %s: %w", qt.name, err)
+		}
+		return nil
+	}
+	applied, err := d.execCAS(ctx, record, table, stmt)
+	if err != nil {
+		return fmt.Errorf("error while executing %s: %w", qt.name, err)
+	}
+	if !applied {
+		return fmt.Errorf("error while executing %s: %w", qt.name, errLWTNotApplied)
+	}
+	return nil
+}
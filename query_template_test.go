@@ -0,0 +1,196 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+)
+
+func TestParseQueryTemplate(t *testing.T) {
+	testCases := []struct {
+		name            string
+		template        string
+		wantCQL         string
+		wantFields      []templateField
+		wantConditional bool
+		wantBatch       bool
+		wantErr         bool
+	}{
+		{
+			name:     "insert with ttl",
+			template: "INSERT INTO ks.users (id, name, source) VALUES (:key.id, :payload.name, :meta.opencdc.collection) USING TTL 86400",
+			wantCQL:  "INSERT INTO ks.users (id, name, source) VALUES (?, ?, ?) USING TTL 86400",
+			wantFields: []templateField{
+				{source: "key", path: "id"},
+				{source: "payload", path: "name"},
+				{source: "meta", path: "opencdc.collection"},
+			},
+		},
+		{
+			name:            "conditional update with a nested field",
+			template:        "UPDATE ks.users SET city = :payload.address.city WHERE id = :key.id IF EXISTS",
+			wantCQL:         "UPDATE ks.users SET city = ? WHERE id = ? IF EXISTS",
+			wantFields:      []templateField{{source: "payload", path: "address.city"}, {source: "key", path: "id"}},
+			wantConditional: true,
+		},
+		{
+			name:       "placeholders in string literals are ignored",
+			template:   "UPDATE ks.users SET note = ':payload.name if', name = :payload.name WHERE id = :key.id",
+			wantCQL:    "UPDATE ks.users SET note = ':payload.name if', name = ? WHERE id = ?",
+			wantFields: []templateField{{source: "payload", path: "name"}, {source: "key", path: "id"}},
+		},
+		{
+			name: "batch",
+			template: "BEGIN BATCH INSERT INTO ks.users_by_id (id, email) VALUES (:key.id, :payload.email); " +
+				"INSERT INTO ks.users_by_email (email, id) VALUES (:payload.email, :key.id); APPLY BATCH",
+			wantCQL: "BEGIN BATCH INSERT INTO ks.users_by_id (id, email) VALUES (?, ?); " +
+				"INSERT INTO ks.users_by_email (email, id) VALUES (?, ?); APPLY BATCH",
+			wantFields: []templateField{
+				{source: "key", path: "id"},
+				{source: "payload", path: "email"},
+				{source: "payload", path: "email"},
+				{source: "key", path: "id"},
+			},
+			wantBatch: true,
+		},
+		{name: "no placeholder", template: "DELETE FROM ks.users WHERE id = 1", wantErr: true},
+		{name: "unterminated string", template: "UPDATE ks.users SET name = 'x WHERE id = :key.id", wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			qt, err := parseQueryTemplate("query.insert", tc.template)
+			if tc.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(qt.cql, tc.wantCQL)
+			is.Equal(qt.fields, tc.wantFields)
+			is.Equal(qt.conditional, tc.wantConditional)
+			is.Equal(qt.batch, tc.wantBatch)
+		})
+	}
+}
+
+func TestDestinationConfig_ParseQueryTemplates(t *testing.T) {
+	is := is.New(t)
+	cfg := DestinationConfig{
+		Mode:        ModeMirror,
+		QueryInsert: "INSERT INTO ks.users (id) VALUES (:key.id) IF NOT EXISTS",
+		QueryDelete: "DELETE FROM ks.users WHERE id = :key.id",
+	}
+	templates, err := cfg.parseQueryTemplates()
+	is.NoErr(err)
+	is.Equal(len(templates), 3)
+	is.True(templates[opencdc.OperationCreate] == templates[opencdc.OperationSnapshot])
+	is.Equal(templates[opencdc.OperationDelete].name, "query.delete")
+	is.True(!templates[opencdc.OperationDelete].idempotent) // templates are not retried by default
+
+	cfg.QueryIdempotent = true
+	templates, err = cfg.parseQueryTemplates()
+	is.NoErr(err)
+	is.True(templates[opencdc.OperationDelete].idempotent)
+
+	cfg.CheckpointTable = "ks.checkpoints"
+	_, err = cfg.parseQueryTemplates()
+	is.True(err != nil) // lightweight transactions can't be batched with the checkpoint
+
+	cfg.CheckpointTable = ""
+	cfg.InputFormat = InputFormatDebezium
+	_, err = cfg.parseQueryTemplates()
+	is.True(err != nil) // lightweight transactions can't use the timestamp of the events
+
+	cfg = DestinationConfig{Mode: ModeChangelog, QueryUpdate: "UPDATE ks.users SET name = :payload.name WHERE id = :key.id"}
+	_, err = cfg.parseQueryTemplates()
+	is.True(err != nil)
+}
+
+func TestQueryTemplate_Statement(t *testing.T) {
+	is := is.New(t)
+	qt, err := parseQueryTemplate("query.update",
+		"UPDATE ks.users SET age = :payload.age, city = :payload.address.city, source = :meta.source WHERE id = :key.id")
+	is.NoErr(err)
+	qt.types = []gocql.TypeInfo{
+		gocql.NewNativeType(4, gocql.TypeInt, ""),
+		gocql.NewNativeType(4, gocql.TypeText, ""),
+		gocql.NewNativeType(4, gocql.TypeText, ""),
+		gocql.NewNativeType(4, gocql.TypeBigInt, ""),
+	}
+
+	rec := opencdc.Record{
+		Operation: opencdc.OperationUpdate,
+		Metadata:  opencdc.Metadata{"source": "crm"},
+		Key:       opencdc.StructuredData{"id": json.Number("1")},
+		Payload: opencdc.Change{After: opencdc.StructuredData{
+			"age":     json.Number("22"),
+			"address": map[string]interface{}{"city": "Paris"},
+		}},
+	}
+	stmt, err := qt.statement(rec)
+	is.NoErr(err)
+	is.Equal(stmt.CQL, "UPDATE ks.users SET age = ?, city = ?, source = ? WHERE id = ?")
+	is.Equal(stmt.Values, []interface{}{int64(22), "Paris", "crm", int64(1)})
+	is.True(stmt.NonIdempotent)
+
+	// payload fields of deletes are taken from the payload before
+	rec.Payload = opencdc.Change{Before: rec.Payload.After}
+	stmt, err = qt.statement(rec)
+	is.NoErr(err)
+	is.Equal(stmt.Values[1], "Paris")
+
+	delete(rec.Metadata, "source")
+	_, err = qt.statement(rec)
+	is.True(errors.Is(err, errInvalidRecord))
+}
+
+func TestDestination_QueryTemplateDryRun(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	output := filepath.Join(t.TempDir(), "dry_run.cql")
+
+	d := &Destination{}
+	err := d.Configure(ctx, map[string]string{
+		"nodes":         "localhost:9042",
+		"keyspace":      "ks",
+		"table":         "users",
+		"dryRun":        "true",
+		"dryRun.output": output,
+		"query.insert":  "INSERT INTO ks.users (id, name) VALUES (:key.id, :payload.name) USING TTL 60",
+	})
+	is.NoErr(err)
+	is.NoErr(d.Open(ctx))
+
+	_, err = d.Write(ctx, []opencdc.Record{{
+		Operation: opencdc.OperationSnapshot,
+		Key:       opencdc.StructuredData{"id": json.Number("1")},
+		Payload:   opencdc.Change{After: opencdc.StructuredData{"name": "john"}},
+	}})
+	is.NoErr(err)
+	is.NoErr(d.Teardown(ctx))
+
+	got, err := os.ReadFile(output)
+	is.NoErr(err)
+	is.Equal(string(got), "INSERT INTO ks.users (id, name) VALUES (1, 'john') USING TTL 60;\n")
+}
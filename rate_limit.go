@@ -0,0 +1,201 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/gocql/gocql"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// adaptiveCooldown is the minimum interval between two decreases of the adaptive rate, so the retries of a
+	// single overload don't collapse the rate.
+	adaptiveCooldown = time.Second
+	// adaptiveRecoveryInterval is the interval without overload after which the adaptive rate is increased.
+	adaptiveRecoveryInterval = time.Second
+	// adaptiveRecoveryStep is the fraction of the maximum rate added to the adaptive rate when it recovers.
+	adaptiveRecoveryStep = 0.05
+)
+
+func (d *DestinationConfig) validateRateLimit() error {
+	if d.RateLimitRecords < 0 {
+		return fmt.Errorf("invalid rateLimit.records %v, should be positive", d.RateLimitRecords)
+	}
+	if d.RateLimitBytes < 0 {
+		return fmt.Errorf("invalid rateLimit.bytes %v, should be positive", d.RateLimitBytes)
+	}
+	if d.RateLimitAdaptive {
+		if d.RateLimitRecords == 0 {
+			return fmt.Errorf("rateLimit.adaptive requires rateLimit.records")
+		}
+		if d.RateLimitAdaptiveMinRecords <= 0 || d.RateLimitAdaptiveMinRecords > d.RateLimitRecords {
+			return fmt.Errorf("invalid rateLimit.adaptive.minRecords %v, should be greater than 0 and at most rateLimit.records",
+				d.RateLimitAdaptiveMinRecords)
+		}
+	}
+	return nil
+}
+
+// rateLimiter limits the rate of the records and bytes written with token buckets. The records rate is adaptive if
+// enabled. All the methods can be called on a nil value, then the rate is unlimited.
+type rateLimiter struct {
+	records *rate.Limiter
+	bytes   *rate.Limiter
+	// adaptive lowers the records rate on overload, it's nil if the rate isn't adaptive
+	adaptive *adaptiveRate
+}
+
+// newRateLimiter returns the rate limiter of the configuration, or nil if the rate is unlimited.
+func (d *DestinationConfig) newRateLimiter() *rateLimiter {
+	if d.RateLimitRecords == 0 && d.RateLimitBytes == 0 {
+		return nil
+	}
+	l := &rateLimiter{}
+	if d.RateLimitRecords > 0 {
+		l.records = rate.NewLimiter(rate.Limit(d.RateLimitRecords), burst(d.RateLimitRecords))
+		if d.RateLimitAdaptive {
+			l.adaptive = &adaptiveRate{
+				limiter: l.records,
+				max:     d.RateLimitRecords,
+				min:     d.RateLimitAdaptiveMinRecords,
+				now:     time.Now,
+			}
+		}
+	}
+	if d.RateLimitBytes > 0 {
+		l.bytes = rate.NewLimiter(rate.Limit(d.RateLimitBytes), d.RateLimitBytes)
+	}
+	return l
+}
+
+// burst returns the bucket size of a rate, it allows a second worth of tokens.
+func burst(r float64) int {
+	return max(1, int(math.Ceil(r)))
+}
+
+// wait blocks until the record can be written without exceeding the rate limits.
+func (l *rateLimiter) wait(ctx context.Context, record opencdc.Record) error {
+	if l == nil {
+		return nil
+	}
+	if l.adaptive != nil {
+		l.adaptive.increase(ctx)
+	}
+	if l.records != nil {
+		if err := l.records.Wait(ctx); err != nil {
+			return fmt.Errorf("error while waiting for the records rate limit: %w", err)
+		}
+	}
+	if l.bytes != nil {
+		// a record bigger than the bucket consumes the whole bucket
+		n := min(recordSize(record), l.bytes.Burst())
+		if err := l.bytes.WaitN(ctx, n); err != nil {
+			return fmt.Errorf("error while waiting for the bytes rate limit: %w", err)
+		}
+	}
+	return nil
+}
+
+// recordSize returns the size in bytes of the key and payload of the record.
+func recordSize(record opencdc.Record) int {
+	size := 0
+	for _, data := range []opencdc.Data{record.Key, record.Payload.Before, record.Payload.After} {
+		if data != nil {
+			size += len(data.Bytes())
+		}
+	}
+	return size
+}
+
+func (l *rateLimiter) ObserveQuery(ctx context.Context, q gocql.ObservedQuery) {
+	if l == nil || l.adaptive == nil {
+		return
+	}
+	l.adaptive.observe(ctx, q.Err)
+}
+
+func (l *rateLimiter) ObserveBatch(ctx context.Context, b gocql.ObservedBatch) {
+	if l == nil || l.adaptive == nil {
+		return
+	}
+	l.adaptive.observe(ctx, b.Err)
+}
+
+// adaptiveRate halves the rate of the limiter when the cluster times out or is overloaded, down to the minimum rate,
+// and increases it by a fraction of the maximum rate after each interval without overload, up to the maximum rate.
+type adaptiveRate struct {
+	limiter *rate.Limiter
+	max     float64
+	min     float64
+	now     func() time.Time
+
+	mu         sync.Mutex
+	lastChange time.Time
+}
+
+// observe decreases the rate if the error is caused by a timeout or an overloaded cluster.
+func (a *adaptiveRate) observe(ctx context.Context, err error) {
+	if err == nil || !isOverloadError(err) {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	current := float64(a.limiter.Limit())
+	if now.Sub(a.lastChange) < adaptiveCooldown || current <= a.min {
+		return
+	}
+	r := max(a.min, current/2)
+	a.setLimit(now, r)
+	sdk.Logger(ctx).Warn().Err(err).Float64("rate", r).Msg("cluster is overloaded, lowering the records rate")
+}
+
+// increase increases the rate if there was no overload during the recovery interval.
+func (a *adaptiveRate) increase(ctx context.Context) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	current := float64(a.limiter.Limit())
+	if current >= a.max || now.Sub(a.lastChange) < adaptiveRecoveryInterval {
+		return
+	}
+	r := min(a.max, current+a.max*adaptiveRecoveryStep)
+	a.setLimit(now, r)
+	sdk.Logger(ctx).Debug().Float64("rate", r).Msg("increasing the records rate")
+}
+
+func (a *adaptiveRate) setLimit(now time.Time, r float64) {
+	a.limiter.SetLimitAt(now, rate.Limit(r))
+	a.limiter.SetBurstAt(now, burst(r))
+	a.lastChange = now
+}
+
+// isOverloadError returns true if the error is caused by a timeout or an overloaded cluster.
+func isOverloadError(err error) bool {
+	if classifyError(err) == errorClassTimeout {
+		return true
+	}
+	var reqErr gocql.RequestError
+	return errors.As(err, &reqErr) && reqErr.Code() == gocql.ErrCodeOverloaded
+}
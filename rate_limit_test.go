@@ -0,0 +1,123 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+	"golang.org/x/time/rate"
+)
+
+// overloadedError is a request error returned by an overloaded coordinator.
+type overloadedError struct{}
+
+func (overloadedError) Code() int       { return gocql.ErrCodeOverloaded }
+func (overloadedError) Message() string { return "overloaded" }
+func (overloadedError) Error() string   { return "overloaded" }
+
+func TestDestinationConfig_ValidateRateLimit(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     DestinationConfig
+		wantErr bool
+	}{
+		{name: "unlimited", cfg: DestinationConfig{RateLimitAdaptiveMinRecords: 1}},
+		{name: "records and bytes", cfg: DestinationConfig{RateLimitRecords: 100, RateLimitBytes: 1024}},
+		{name: "adaptive", cfg: DestinationConfig{RateLimitRecords: 100, RateLimitAdaptive: true, RateLimitAdaptiveMinRecords: 1}},
+		{name: "negative records", cfg: DestinationConfig{RateLimitRecords: -1}, wantErr: true},
+		{name: "negative bytes", cfg: DestinationConfig{RateLimitBytes: -1}, wantErr: true},
+		{name: "adaptive without records", cfg: DestinationConfig{RateLimitAdaptive: true, RateLimitAdaptiveMinRecords: 1}, wantErr: true},
+		{name: "min above max", cfg: DestinationConfig{RateLimitRecords: 10, RateLimitAdaptive: true, RateLimitAdaptiveMinRecords: 20}, wantErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(tc.cfg.validateRateLimit() != nil, tc.wantErr)
+		})
+	}
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	record := opencdc.Record{Key: opencdc.RawData("12345"), Payload: opencdc.Change{After: opencdc.RawData("12345")}}
+
+	var l *rateLimiter
+	is.NoErr(l.wait(ctx, record)) // unlimited
+
+	is.True((&DestinationConfig{}).newRateLimiter() == nil)
+
+	l = (&DestinationConfig{RateLimitRecords: 2, RateLimitBytes: 100}).newRateLimiter()
+	is.NoErr(l.wait(ctx, record))
+	is.NoErr(l.wait(ctx, record))
+	// the bucket of records is empty, and 20 bytes were consumed
+	is.True(l.records.Tokens() < 1)
+	is.True(l.bytes.Tokens() < 81)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	is.True(l.wait(canceled, record) != nil)
+
+	// records bigger than the bucket consume the whole bucket
+	l = (&DestinationConfig{RateLimitBytes: 4}).newRateLimiter()
+	is.NoErr(l.wait(ctx, record))
+}
+
+func TestAdaptiveRate(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	now := time.Now()
+	limiter := rate.NewLimiter(100, 100)
+	a := &adaptiveRate{limiter: limiter, max: 100, min: 10, now: func() time.Time { return now }}
+
+	a.observe(ctx, errors.New("unrelated"))
+	is.Equal(limiter.Limit(), rate.Limit(100))
+
+	a.observe(ctx, overloadedError{})
+	is.Equal(limiter.Limit(), rate.Limit(50))
+	is.Equal(limiter.Burst(), 50)
+
+	// retries of the same overload don't lower the rate again
+	a.observe(ctx, overloadedError{})
+	is.Equal(limiter.Limit(), rate.Limit(50))
+
+	now = now.Add(adaptiveCooldown)
+	a.observe(ctx, gocql.ErrTimeoutNoResponse)
+	is.Equal(limiter.Limit(), rate.Limit(25))
+	now = now.Add(adaptiveCooldown)
+	a.observe(ctx, &gocql.RequestErrWriteTimeout{})
+	is.Equal(limiter.Limit(), rate.Limit(12.5))
+	now = now.Add(adaptiveCooldown)
+	a.observe(ctx, overloadedError{})
+	is.Equal(limiter.Limit(), rate.Limit(10)) // minimum rate
+
+	// the rate is increased after each interval without overload
+	a.increase(ctx)
+	is.Equal(limiter.Limit(), rate.Limit(10))
+	now = now.Add(adaptiveRecoveryInterval)
+	a.increase(ctx)
+	is.Equal(limiter.Limit(), rate.Limit(15))
+	for i := 0; i < 30; i++ {
+		now = now.Add(adaptiveRecoveryInterval)
+		a.increase(ctx)
+	}
+	is.Equal(limiter.Limit(), rate.Limit(100)) // maximum rate
+}
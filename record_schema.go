@@ -0,0 +1,420 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	sdkschema "github.com/conduitio/conduit-connector-sdk/schema"
+	"github.com/gocql/gocql"
+	"github.com/hamba/avro/v2"
+)
+
+const (
+	createTableFromSchemaQuery = "CREATE TABLE IF NOT EXISTS %s (%s, PRIMARY KEY ((%s)))"
+	addColumnQuery             = "ALTER TABLE %s ADD %s %s"
+)
+
+func (d *DestinationConfig) validateSchema() error {
+	if !d.SchemaEnabled && (d.SchemaValidate || d.SchemaAutoCreate || d.SchemaEvolve) {
+		return fmt.Errorf("schema.validate, schema.autoCreate and schema.evolve require schema.enabled")
+	}
+	return nil
+}
+
+// recordSchemas are the schemas of the key and payload of a record, a schema is nil if the record doesn't reference
+// one in its metadata.
+type recordSchemas struct {
+	key     *avro.RecordSchema
+	payload *avro.RecordSchema
+	// id identifies the schemas, ex: users-key:1/users-value:3
+	id string
+}
+
+// applyRecordSchema fetches the schemas referenced by the opencdc.key.schema.* and opencdc.payload.schema.* metadata
+// of the record, and uses them to type the values of the record. Depending on the configuration, the table is also
+// created, validated or evolved using the schemas, once per table and schema versions.
+func (d *Destination) applyRecordSchema(ctx context.Context, record opencdc.Record, table string) (opencdc.Record, error) {
+	schemas, err := d.recordSchemas(ctx, record.Metadata)
+	if err != nil {
+		return record, err
+	}
+	if schemas.key == nil && schemas.payload == nil {
+		return record, nil
+	}
+
+	if d.config.SchemaValidate || d.config.SchemaAutoCreate || d.config.SchemaEvolve {
+		if d.checkedSchemas == nil {
+			d.checkedSchemas = make(map[string]bool)
+		}
+		checked := table + "/" + schemas.id
+		if !d.checkedSchemas[checked] {
+			err = d.syncTableSchema(ctx, table, schemas)
+			if err != nil {
+				return record, err
+			}
+			d.checkedSchemas[checked] = true
+		}
+	}
+
+	if schemas.key != nil {
+		if key, ok := record.Key.(opencdc.StructuredData); ok {
+			record.Key, err = typeStructuredData(key, schemas.key)
+			if err != nil {
+				return record, fmt.Errorf("%w: key doesn't match its schema: %w", errInvalidRecord, err)
+			}
+		}
+	}
+	if schemas.payload != nil {
+		for _, data := range []*opencdc.Data{&record.Payload.Before, &record.Payload.After} {
+			sd, ok := (*data).(opencdc.StructuredData)
+			if !ok {
+				continue
+			}
+			*data, err = typeStructuredData(sd, schemas.payload)
+			if err != nil {
+				return record, fmt.Errorf("%w: payload doesn't match its schema: %w", errInvalidRecord, err)
+			}
+		}
+	}
+	return record, nil
+}
+
+// recordSchemas returns the key and payload schemas referenced by the metadata.
+func (d *Destination) recordSchemas(ctx context.Context, metadata opencdc.Metadata) (recordSchemas, error) {
+	var schemas recordSchemas
+	var ids []string
+	for _, s := range []struct {
+		subject func() (string, error)
+		version func() (int, error)
+		schema  **avro.RecordSchema
+	}{
+		{subject: metadata.GetKeySchemaSubject, version: metadata.GetKeySchemaVersion, schema: &schemas.key},
+		{subject: metadata.GetPayloadSchemaSubject, version: metadata.GetPayloadSchemaVersion, schema: &schemas.payload},
+	} {
+		subject, err := s.subject()
+		if errors.Is(err, opencdc.ErrMetadataFieldNotFound) {
+			continue
+		}
+		if err != nil {
+			return recordSchemas{}, fmt.Errorf("%w: %w", errInvalidRecord, err)
+		}
+		version, err := s.version()
+		if err != nil {
+			return recordSchemas{}, fmt.Errorf("%w: %w", errInvalidRecord, err)
+		}
+		*s.schema, err = d.fetchSchema(ctx, subject, version)
+		if err != nil {
+			return recordSchemas{}, err
+		}
+		ids = append(ids, subject+":"+strconv.Itoa(version))
+	}
+	schemas.id = strings.Join(ids, "/")
+	return schemas, nil
+}
+
+// fetchSchema returns the Avro record schema with the given subject and version from the schema service, parsed
+// schemas are cached.
+func (d *Destination) fetchSchema(ctx context.Context, subject string, version int) (*avro.RecordSchema, error) {
+	id := subject + ":" + strconv.Itoa(version)
+	if s, ok := d.schemas[id]; ok {
+		return s, nil
+	}
+	s, err := sdkschema.Get(ctx, subject, version)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching schema %s: %w", id, err)
+	}
+	if s.Type != sdkschema.TypeAvro {
+		return nil, fmt.Errorf("unsupported type %q of schema %s, only avro is supported", s.Type, id)
+	}
+	parsed, err := avro.Parse(string(s.Bytes))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", id, err)
+	}
+	rs, ok := parsed.(*avro.RecordSchema)
+	if !ok {
+		return nil, fmt.Errorf("schema %s should be a record schema, got %s", id, parsed.Type())
+	}
+	if d.schemas == nil {
+		d.schemas = make(map[string]*avro.RecordSchema)
+	}
+	d.schemas[id] = rs
+	sdk.Logger(ctx).Debug().Str("schema", id).Msg("fetched record schema")
+	return rs, nil
+}
+
+// syncTableSchema creates the table from the schemas if it doesn't exist and schema.autoCreate is enabled, otherwise
+// it validates the table against the schemas, adding the missing columns if schema.evolve is enabled.
+func (d *Destination) syncTableSchema(ctx context.Context, table string, schemas recordSchemas) error {
+	if d.queryBuilder.tables == nil {
+		return nil
+	}
+	meta, err := d.queryBuilder.tables(table)
+	if errors.Is(err, errTableNotFound) && d.config.SchemaAutoCreate {
+		return d.createTableFromSchema(ctx, table, schemas)
+	}
+	if err != nil {
+		return err
+	}
+
+	missing, err := validateTableSchema(meta, schemas)
+	if err != nil && d.config.SchemaValidate {
+		return fmt.Errorf("%w: table %s doesn't match the record schema: %w", errInvalidRecord, table, err)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	if !d.config.SchemaEvolve {
+		if d.config.SchemaValidate {
+			return fmt.Errorf("%w: table %s is missing the columns %s of the record schema", errInvalidRecord, table, fieldNames(missing))
+		}
+		return nil
+	}
+	for _, f := range missing {
+		colType, err := cqlTypeOf(f.Type())
+		if err != nil {
+			return fmt.Errorf("can't add column %q to table %s: %w", f.Name(), table, err)
+		}
+		err = d.session.Query(fmt.Sprintf(addColumnQuery, table, f.Name(), colType)).Exec()
+		if err != nil {
+			return fmt.Errorf("error while adding column %q to table %s: %w", f.Name(), table, err)
+		}
+		sdk.Logger(ctx).Info().Str("table", table).Str("column", f.Name()).Str("type", colType).Msg("added column from the record schema")
+	}
+	return nil
+}
+
+// createTableFromSchema creates the table with the key fields as the partition key, and the payload fields as columns.
+func (d *Destination) createTableFromSchema(ctx context.Context, table string, schemas recordSchemas) error {
+	if schemas.key == nil {
+		return fmt.Errorf("can't create table %s without a key schema", table)
+	}
+	stmt, err := buildCreateTableFromSchema(table, schemas)
+	if err != nil {
+		return fmt.Errorf("can't create table %s: %w", table, err)
+	}
+	err = d.session.Query(stmt).Exec()
+	if err != nil {
+		return fmt.Errorf("error while creating table %s: %w", table, err)
+	}
+	sdk.Logger(ctx).Info().Str("table", table).Msg("created table from the record schema")
+	return nil
+}
+
+// buildCreateTableFromSchema returns the statement creating the table from the key and payload schemas.
+func buildCreateTableFromSchema(table string, schemas recordSchemas) (string, error) {
+	var columns, keyColumns []string
+	seen := make(map[string]bool)
+	for _, rs := range []*avro.RecordSchema{schemas.key, schemas.payload} {
+		if rs == nil {
+			continue
+		}
+		for _, f := range rs.Fields() {
+			if seen[f.Name()] {
+				continue
+			}
+			seen[f.Name()] = true
+			colType, err := cqlTypeOf(f.Type())
+			if err != nil {
+				return "", fmt.Errorf("field %q: %w", f.Name(), err)
+			}
+			columns = append(columns, f.Name()+" "+colType)
+			if rs == schemas.key {
+				keyColumns = append(keyColumns, f.Name())
+			}
+		}
+	}
+	return fmt.Sprintf(createTableFromSchemaQuery, table, strings.Join(columns, ", "), strings.Join(keyColumns, ", ")), nil
+}
+
+// validateTableSchema returns the payload fields that have no column in the table, and an error if a key field is not
+// a primary key column, or if the type of a field is not compatible with the type of its column.
+func validateTableSchema(meta *gocql.TableMetadata, schemas recordSchemas) ([]*avro.Field, error) {
+	var missing []*avro.Field
+	var errs []error
+	for _, rs := range []*avro.RecordSchema{schemas.key, schemas.payload} {
+		if rs == nil {
+			continue
+		}
+		for _, f := range rs.Fields() {
+			// unquoted identifiers are case-insensitive
+			col, ok := meta.Columns[strings.ToLower(f.Name())]
+			switch {
+			case !ok && rs == schemas.key:
+				errs = append(errs, fmt.Errorf("key field %q is not a column", f.Name()))
+			case !ok:
+				missing = append(missing, f)
+			case rs == schemas.key && !isPrimaryKey(meta, col.Name):
+				errs = append(errs, fmt.Errorf("key field %q is not a primary key column", f.Name()))
+			case col.Type != nil && !slices.Contains(compatibleCQLTypes(f.Type()), col.Type.Type()):
+				errs = append(errs, fmt.Errorf("field %q of type %s is not compatible with column type %s", f.Name(), f.Type().Type(), col.Type.Type()))
+			}
+		}
+	}
+	return missing, errors.Join(errs...)
+}
+
+func isPrimaryKey(meta *gocql.TableMetadata, column string) bool {
+	for _, cols := range [][]*gocql.ColumnMetadata{meta.PartitionKey, meta.ClusteringColumns} {
+		for _, c := range cols {
+			if c.Name == column {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func fieldNames(fields []*avro.Field) string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// nonNullSchema returns the non null type of a nullable union, or the schema itself.
+func nonNullSchema(s avro.Schema) avro.Schema {
+	us, ok := s.(*avro.UnionSchema)
+	if !ok || !us.Nullable() {
+		return s
+	}
+	for _, t := range us.Types() {
+		if t.Type() != avro.Null {
+			return t
+		}
+	}
+	return s
+}
+
+// logicalType returns the logical type of the schema, or an empty string if it has none.
+func logicalType(s avro.Schema) avro.LogicalType {
+	ls, ok := s.(avro.LogicalTypeSchema)
+	if !ok || ls.Logical() == nil {
+		return ""
+	}
+	return ls.Logical().Type()
+}
+
+// cqlTypeOf returns the CQL type used to store values of the Avro schema.
+func cqlTypeOf(s avro.Schema) (string, error) {
+	s = nonNullSchema(s)
+	switch logicalType(s) {
+	case avro.Date:
+		return "date", nil
+	case avro.TimeMillis, avro.TimeMicros:
+		return "time", nil
+	case avro.TimestampMillis, avro.TimestampMicros, avro.LocalTimestampMillis, avro.LocalTimestampMicros:
+		return "timestamp", nil
+	case avro.UUID:
+		return "uuid", nil
+	case avro.Decimal:
+		return "decimal", nil
+	case avro.Duration:
+		return "duration", nil
+	}
+	switch s.Type() {
+	case avro.Int:
+		return "int", nil
+	case avro.Long:
+		return "bigint", nil
+	case avro.Float:
+		return "float", nil
+	case avro.Double:
+		return "double", nil
+	case avro.String, avro.Enum:
+		return "text", nil
+	case avro.Bytes, avro.Fixed:
+		return "blob", nil
+	case avro.Boolean:
+		return "boolean", nil
+	case avro.Array:
+		items, err := cqlTypeOf(s.(*avro.ArraySchema).Items())
+		if err != nil {
+			return "", err
+		}
+		return "list<" + frozen(items) + ">", nil
+	case avro.Map:
+		values, err := cqlTypeOf(s.(*avro.MapSchema).Values())
+		if err != nil {
+			return "", err
+		}
+		return "map<text, " + frozen(values) + ">", nil
+	case avro.Record:
+		// UDTs are not created from the schema, since their name and keyspace can't be derived from it
+		return "", fmt.Errorf("nested record %s needs an existing UDT, create the UDT and its column manually",
+			s.(*avro.RecordSchema).FullName())
+	default:
+		return "", fmt.Errorf("unsupported type %s", s.Type())
+	}
+}
+
+// frozen returns the type frozen if it's a collection, since nested collections should be frozen.
+func frozen(t string) string {
+	if strings.HasSuffix(t, ">") {
+		return "frozen<" + t + ">"
+	}
+	return t
+}
+
+// compatibleCQLTypes returns the CQL types that can store values of the Avro schema.
+func compatibleCQLTypes(s avro.Schema) []gocql.Type {
+	s = nonNullSchema(s)
+	switch logicalType(s) {
+	case avro.Date:
+		return []gocql.Type{gocql.TypeDate, gocql.TypeTimestamp}
+	case avro.TimeMillis, avro.TimeMicros:
+		return []gocql.Type{gocql.TypeTime}
+	case avro.TimestampMillis, avro.TimestampMicros, avro.LocalTimestampMillis, avro.LocalTimestampMicros:
+		return []gocql.Type{gocql.TypeTimestamp, gocql.TypeDate}
+	case avro.UUID:
+		return []gocql.Type{gocql.TypeUUID, gocql.TypeTimeUUID, gocql.TypeText, gocql.TypeVarchar}
+	case avro.Decimal:
+		return []gocql.Type{gocql.TypeDecimal}
+	case avro.Duration:
+		return []gocql.Type{gocql.TypeDuration}
+	}
+	switch s.Type() {
+	case avro.Int:
+		return []gocql.Type{gocql.TypeInt, gocql.TypeBigInt, gocql.TypeVarint, gocql.TypeCounter}
+	case avro.Long:
+		return []gocql.Type{gocql.TypeBigInt, gocql.TypeVarint, gocql.TypeCounter}
+	case avro.Float:
+		return []gocql.Type{gocql.TypeFloat, gocql.TypeDouble}
+	case avro.Double:
+		return []gocql.Type{gocql.TypeDouble}
+	case avro.String, avro.Enum:
+		return []gocql.Type{gocql.TypeText, gocql.TypeVarchar, gocql.TypeAscii, gocql.TypeInet}
+	case avro.Bytes, avro.Fixed:
+		return []gocql.Type{gocql.TypeBlob}
+	case avro.Boolean:
+		return []gocql.Type{gocql.TypeBoolean}
+	case avro.Array:
+		return []gocql.Type{gocql.TypeList, gocql.TypeSet}
+	case avro.Map:
+		return []gocql.Type{gocql.TypeMap}
+	case avro.Record:
+		return []gocql.Type{gocql.TypeUDT}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	sdkschema "github.com/conduitio/conduit-connector-sdk/schema"
+	"github.com/gocql/gocql"
+	"github.com/hamba/avro/v2"
+	"github.com/matryer/is"
+	"gopkg.in/inf.v0"
+)
+
+const testPayloadSchema = `{
+	"type": "record",
+	"name": "user",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": ["null", "int"]},
+		{"name": "created", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "uid", "type": {"type": "string", "logicalType": "uuid"}},
+		{"name": "balance", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+		{"name": "tags", "type": {"type": "array", "items": "long"}}
+	]
+}`
+
+const testKeySchema = `{"type": "record", "name": "user_key", "fields": [{"name": "id", "type": "long"}]}`
+
+func parseTestSchema(t *testing.T, s string) *avro.RecordSchema {
+	parsed, err := avro.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.(*avro.RecordSchema)
+}
+
+func TestTypeStructuredData(t *testing.T) {
+	is := is.New(t)
+	data := opencdc.StructuredData{
+		"name":    "john",
+		"age":     float64(22),
+		"created": json.Number("1704067200000"),
+		"uid":     "f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		"balance": "12.50",
+		"tags":    []interface{}{float64(1), float64(2)},
+		"other":   float64(1.5),
+	}
+
+	typed, err := typeStructuredData(data, parseTestSchema(t, testPayloadSchema))
+	is.NoErr(err)
+	is.Equal(typed["name"], "john")
+	is.Equal(typed["age"], int32(22))
+	is.Equal(typed["created"], time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	uid, _ := gocql.ParseUUID("f47ac10b-58cc-4372-a567-0e02b2c3d479")
+	is.Equal(typed["uid"], uid)
+	is.Equal(typed["balance"].(*inf.Dec).String(), "12.50")
+	is.Equal(typed["tags"], []interface{}{int64(1), int64(2)})
+	is.Equal(typed["other"], float64(1.5)) // not in the schema
+	is.Equal(data["age"], float64(22))     // the original data is left unchanged
+
+	_, err = typeStructuredData(opencdc.StructuredData{"age": 1.5}, parseTestSchema(t, testPayloadSchema))
+	is.True(err != nil)
+}
+
+func TestTypeStructuredData_NestedRecord(t *testing.T) {
+	is := is.New(t)
+	rs := parseTestSchema(t, `{
+		"type": "record",
+		"name": "user",
+		"fields": [{"name": "address", "type": {
+			"type": "record",
+			"name": "address",
+			"fields": [{"name": "city", "type": "string"}, {"name": "zip", "type": "int"}]
+		}}]
+	}`)
+	typed, err := typeStructuredData(opencdc.StructuredData{
+		"address": map[string]interface{}{"city": "Paris", "zip": json.Number("75001")},
+	}, rs)
+	is.NoErr(err)
+	address, ok := typed["address"].(map[string]interface{})
+	is.True(ok) // nested records are plain maps
+	is.Equal(address, map[string]interface{}{"city": "Paris", "zip": int32(75001)})
+
+	udt := gocql.UDTTypeInfo{
+		NativeType: gocql.NewNativeType(4, gocql.TypeUDT, ""),
+		Name:       "address",
+		Elements: []gocql.UDTField{
+			{Name: "city", Type: gocql.NewNativeType(4, gocql.TypeText, "")},
+			{Name: "zip", Type: gocql.NewNativeType(4, gocql.TypeInt, "")},
+		},
+	}
+	_, err = gocql.Marshal(udt, typed["address"])
+	is.NoErr(err)
+}
+
+func TestValidateTableSchema(t *testing.T) {
+	is := is.New(t)
+	native := func(typ gocql.Type) gocql.TypeInfo { return gocql.NewNativeType(4, typ, "") }
+	id := &gocql.ColumnMetadata{Name: "id", Kind: gocql.ColumnPartitionKey, Type: native(gocql.TypeBigInt)}
+	meta := &gocql.TableMetadata{
+		PartitionKey: []*gocql.ColumnMetadata{id},
+		Columns: map[string]*gocql.ColumnMetadata{
+			"id":      id,
+			"name":    {Name: "name", Type: native(gocql.TypeText)},
+			"age":     {Name: "age", Type: native(gocql.TypeInt)},
+			"created": {Name: "created", Type: native(gocql.TypeTimestamp)},
+			"uid":     {Name: "uid", Type: native(gocql.TypeUUID)},
+		},
+	}
+	schemas := recordSchemas{key: parseTestSchema(t, testKeySchema), payload: parseTestSchema(t, testPayloadSchema)}
+
+	missing, err := validateTableSchema(meta, schemas)
+	is.NoErr(err)
+	is.Equal(fieldNames(missing), "balance, tags")
+
+	meta.Columns["age"] = &gocql.ColumnMetadata{Name: "age", Type: native(gocql.TypeBoolean)}
+	_, err = validateTableSchema(meta, schemas)
+	is.True(err != nil)
+}
+
+func TestBuildCreateTableFromSchema(t *testing.T) {
+	is := is.New(t)
+	schemas := recordSchemas{key: parseTestSchema(t, testKeySchema), payload: parseTestSchema(t, testPayloadSchema)}
+	stmt, err := buildCreateTableFromSchema("ks.users", schemas)
+	is.NoErr(err)
+	is.Equal(stmt, "CREATE TABLE IF NOT EXISTS ks.users (id bigint, name text, age int, created timestamp, uid uuid, "+
+		"balance decimal, tags list<bigint>, PRIMARY KEY ((id)))")
+
+	// nested records need an existing UDT
+	schemas.payload = parseTestSchema(t, `{"type": "record", "name": "user", "fields": [
+		{"name": "address", "type": {"type": "record", "name": "address", "fields": [{"name": "city", "type": "string"}]}}
+	]}`)
+	_, err = buildCreateTableFromSchema("ks.users", schemas)
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "nested record address needs an existing UDT"))
+}
+
+func TestDestination_ApplyRecordSchema(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	keySchema, err := sdkschema.Create(ctx, sdkschema.TypeAvro, "users-key", []byte(testKeySchema))
+	is.NoErr(err)
+	payloadSchema, err := sdkschema.Create(ctx, sdkschema.TypeAvro, "users-value", []byte(testPayloadSchema))
+	is.NoErr(err)
+
+	rec := opencdc.Record{
+		Metadata: opencdc.Metadata{},
+		Key:      opencdc.StructuredData{"id": float64(1)},
+		Payload: opencdc.Change{
+			After: opencdc.StructuredData{"name": "john", "age": float64(22)},
+		},
+	}
+	sdkschema.AttachKeySchemaToRecord(rec, keySchema)
+	sdkschema.AttachPayloadSchemaToRecord(rec, payloadSchema)
+
+	d := Destination{config: DestinationConfig{SchemaEnabled: true}}
+	got, err := d.applyRecordSchema(ctx, rec, "ks.users")
+	is.NoErr(err)
+	is.Equal(got.Key, opencdc.StructuredData{"id": int64(1)})
+	is.Equal(got.Payload.After, opencdc.StructuredData{"name": "john", "age": int32(22)})
+
+	// records without schema metadata are left as is
+	rec.Metadata = opencdc.Metadata{}
+	got, err = d.applyRecordSchema(ctx, rec, "ks.users")
+	is.NoErr(err)
+	is.Equal(got.Key, opencdc.StructuredData{"id": float64(1)})
+
+	rec.Metadata = opencdc.Metadata{opencdc.MetadataPayloadSchemaSubject: "unknown", opencdc.MetadataPayloadSchemaVersion: "1"}
+	_, err = d.applyRecordSchema(ctx, rec, "ks.users")
+	is.True(err != nil)
+	is.True(!errors.Is(err, errInvalidRecord))
+}
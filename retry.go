@@ -0,0 +1,96 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"errors"
+
+	sdk "github.com/conduitio/conduit-connector-sdk"
+	"github.com/gocql/gocql"
+)
+
+// retryPolicy wraps a gocql retry policy, it only retries idempotent statements and rethrows errors that are not
+// transient, so a statement that might have been applied (like a lightweight transaction) is never applied twice.
+type retryPolicy struct {
+	gocql.RetryPolicy
+}
+
+// idempotentQuery is implemented by gocql queries and batches.
+type idempotentQuery interface {
+	IsIdempotent() bool
+}
+
+func (p *retryPolicy) Attempt(q gocql.RetryableQuery) bool {
+	if iq, ok := q.(idempotentQuery); ok && !iq.IsIdempotent() {
+		return false
+	}
+	if !p.RetryPolicy.Attempt(q) {
+		return false
+	}
+	sdk.Logger(q.Context()).Debug().Int("attempt", q.Attempts()).Msg("retrying query")
+	return true
+}
+
+func (p *retryPolicy) GetRetryType(err error) gocql.RetryType {
+	if !isRetryableError(err) {
+		return gocql.Rethrow
+	}
+	return p.RetryPolicy.GetRetryType(err)
+}
+
+// newRetryPolicy returns the retry policy configured for the connector, or nil if retries are disabled.
+func (d *DestinationConfig) newRetryPolicy() (gocql.RetryPolicy, error) {
+	if len(d.RetryDowngradingConsistency) > 0 {
+		levels := make([]gocql.Consistency, len(d.RetryDowngradingConsistency))
+		for i, l := range d.RetryDowngradingConsistency {
+			c, err := gocql.ParseConsistencyWrapper(l)
+			if err != nil {
+				return nil, err
+			}
+			levels[i] = c
+		}
+		return &retryPolicy{&gocql.DowngradingConsistencyRetryPolicy{ConsistencyLevelsToTry: levels}}, nil
+	}
+	if d.RetryMaxRetries == 0 {
+		return nil, nil
+	}
+	return &retryPolicy{&gocql.ExponentialBackoffRetryPolicy{
+		NumRetries: d.RetryMaxRetries,
+		Min:        d.RetryMinBackoff,
+		Max:        d.RetryMaxBackoff,
+	}}, nil
+}
+
+// isRetryableError returns true if the error is transient, meaning that the same statement could succeed if retried.
+func isRetryableError(err error) bool {
+	var unavailableErr *gocql.RequestErrUnavailable
+	var writeTimeoutErr *gocql.RequestErrWriteTimeout
+	var readTimeoutErr *gocql.RequestErrReadTimeout
+	var reqErr gocql.RequestError
+	switch {
+	case errors.Is(err, gocql.ErrTimeoutNoResponse), errors.Is(err, gocql.ErrNoConnections),
+		errors.Is(err, gocql.ErrConnectionClosed):
+		return true
+	case errors.As(err, &unavailableErr), errors.As(err, &writeTimeoutErr), errors.As(err, &readTimeoutErr):
+		return true
+	case errors.As(err, &reqErr):
+		switch reqErr.Code() {
+		case gocql.ErrCodeUnavailable, gocql.ErrCodeOverloaded, gocql.ErrCodeBootstrapping,
+			gocql.ErrCodeWriteTimeout, gocql.ErrCodeReadTimeout, gocql.ErrCodeTruncate:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+)
+
+type fakeRetryableQuery struct {
+	attempts    int
+	idempotent  bool
+	consistency gocql.Consistency
+}
+
+func (q *fakeRetryableQuery) Attempts() int                      { return q.attempts }
+func (q *fakeRetryableQuery) SetConsistency(c gocql.Consistency) { q.consistency = c }
+func (q *fakeRetryableQuery) GetConsistency() gocql.Consistency  { return q.consistency }
+func (q *fakeRetryableQuery) Context() context.Context           { return context.Background() }
+func (q *fakeRetryableQuery) IsIdempotent() bool                 { return q.idempotent }
+
+func TestRetryPolicy(t *testing.T) {
+	is := is.New(t)
+	cfg := DestinationConfig{
+		RetryMaxRetries: 2,
+		RetryMinBackoff: time.Millisecond,
+		RetryMaxBackoff: time.Millisecond,
+	}
+	policy, err := cfg.newRetryPolicy()
+	is.NoErr(err)
+
+	// non-idempotent queries are never retried
+	is.True(!policy.Attempt(&fakeRetryableQuery{attempts: 1, idempotent: false}))
+	is.True(policy.Attempt(&fakeRetryableQuery{attempts: 1, idempotent: true}))
+	is.True(!policy.Attempt(&fakeRetryableQuery{attempts: 3, idempotent: true}))
+
+	is.Equal(policy.GetRetryType(&gocql.RequestErrWriteTimeout{}), gocql.RetryNextHost)
+	is.Equal(policy.GetRetryType(errors.New("syntax error")), gocql.Rethrow)
+
+	cfg.RetryMaxRetries = 0
+	policy, err = cfg.newRetryPolicy()
+	is.NoErr(err)
+	is.Equal(policy, nil)
+}
+
+func TestRetryPolicy_DowngradingConsistency(t *testing.T) {
+	is := is.New(t)
+	cfg := DestinationConfig{RetryDowngradingConsistency: []string{"QUORUM", "ONE"}}
+	policy, err := cfg.newRetryPolicy()
+	is.NoErr(err)
+
+	q := &fakeRetryableQuery{attempts: 2, idempotent: true, consistency: gocql.All}
+	is.True(policy.Attempt(q))
+	is.Equal(q.consistency, gocql.One)
+
+	cfg.RetryDowngradingConsistency = []string{"MOST"}
+	_, err = cfg.newRetryPolicy()
+	is.True(err != nil)
+}
+
+func TestIsRetryableError(t *testing.T) {
+	is := is.New(t)
+	is.True(isRetryableError(fmt.Errorf("error while inserting data: %w", &gocql.RequestErrUnavailable{})))
+	is.True(isRetryableError(fmt.Errorf("error while inserting data: %w", gocql.ErrTimeoutNoResponse)))
+	is.True(!isRetryableError(fmt.Errorf("error while inserting data: %w", errLWTNotApplied)))
+	is.True(!isRetryableError(gocql.MarshalError("can not marshal")))
+}
@@ -0,0 +1,98 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gocql/gocql"
+)
+
+var errTableNotFound = errors.New("table not found")
+
+// tableMetadataFn returns the metadata of a table given its fully qualified name (keyspace.table).
+type tableMetadataFn func(table string) (*gocql.TableMetadata, error)
+
+// tableMetadata returns the metadata of a table given its fully qualified name (keyspace.table). The metadata is
+// read from system_schema and cached by gocql, which refreshes it when the schema changes.
+func (d *Destination) tableMetadata(table string) (*gocql.TableMetadata, error) {
+	keyspace, name := splitTableName(table)
+	ksMeta, err := d.session.KeyspaceMetadata(keyspace)
+	if err != nil {
+		return nil, fmt.Errorf("error while getting metadata of keyspace %q: %w", keyspace, err)
+	}
+	tableMeta, ok := ksMeta.Tables[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errTableNotFound, table)
+	}
+	return tableMeta, nil
+}
+
+// schemaColumnOrder returns the columns of the table in the order they are defined in its schema: the partition key
+// columns, the clustering columns, then the remaining columns.
+func schemaColumnOrder(table *gocql.TableMetadata) []string {
+	columns := make([]string, 0, len(table.Columns))
+	seen := make(map[string]bool, len(table.Columns))
+	for _, c := range table.PartitionKey {
+		columns = append(columns, c.Name)
+		seen[c.Name] = true
+	}
+	for _, c := range table.ClusteringColumns {
+		columns = append(columns, c.Name)
+		seen[c.Name] = true
+	}
+	for _, c := range table.OrderedColumns {
+		if !seen[c] {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// splitTableName splits a fully qualified table name into the keyspace and table names, as they are stored in the
+// schema: quoted identifiers are unquoted, and unquoted identifiers are lower-cased since they are case-insensitive.
+func splitTableName(table string) (string, string) {
+	keyspace, rest := splitIdentifier(table)
+	name, _ := splitIdentifier(strings.TrimPrefix(rest, "."))
+	return keyspace, name
+}
+
+// splitIdentifier returns the first identifier in s and the remaining string.
+func splitIdentifier(s string) (string, string) {
+	if !strings.HasPrefix(s, `"`) {
+		i := strings.IndexByte(s, '.')
+		if i < 0 {
+			return strings.ToLower(s), ""
+		}
+		return strings.ToLower(s[:i]), s[i:]
+	}
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != '"' {
+			sb.WriteByte(s[i])
+			continue
+		}
+		// escaped quote
+		if i+1 < len(s) && s[i+1] == '"' {
+			sb.WriteByte('"')
+			i++
+			continue
+		}
+		return sb.String(), s[i+1:]
+	}
+	return sb.String(), ""
+}
@@ -0,0 +1,42 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestSplitTableName(t *testing.T) {
+	testCases := []struct {
+		table        string
+		wantKeyspace string
+		wantTable    string
+	}{
+		{table: "ks.users", wantKeyspace: "ks", wantTable: "users"},
+		{table: "KS.Users", wantKeyspace: "ks", wantTable: "users"},
+		{table: `"MyKs"."My.Table"`, wantKeyspace: "MyKs", wantTable: "My.Table"},
+		{table: `ks."Quoted""Name"`, wantKeyspace: "ks", wantTable: `Quoted"Name`},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.table, func(t *testing.T) {
+			is := is.New(t)
+			keyspace, table := splitTableName(tt.table)
+			is.Equal(keyspace, tt.wantKeyspace)
+			is.Equal(table, tt.wantTable)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/rs/zerolog"
+)
+
+func (d *DestinationConfig) validateTracing() error {
+	if d.TracingSampleRate < 0 || d.TracingSampleRate > 1 {
+		return fmt.Errorf("invalid tracing.sampleRate %v, should be between 0 and 1", d.TracingSampleRate)
+	}
+	return nil
+}
+
+// sampleTrace returns true if the next statement should be traced by Cassandra.
+func (d *Destination) sampleTrace() bool {
+	return d.tracer != nil && rand.Float64() < d.config.TracingSampleRate //nolint:gosec // sampling isn't security sensitive
+}
+
+// slowQueryLogger logs the queries and batches that take longer than the threshold.
+type slowQueryLogger struct {
+	threshold time.Duration
+	logger    *zerolog.Logger
+}
+
+func (l *slowQueryLogger) ObserveQuery(ctx context.Context, q gocql.ObservedQuery) {
+	l.observe(ctx, q.Statement, q.Host, q.Start, q.End, q.Attempt, q.Err)
+}
+
+func (l *slowQueryLogger) ObserveBatch(ctx context.Context, b gocql.ObservedBatch) {
+	l.observe(ctx, strings.Join(b.Statements, "; "), b.Host, b.Start, b.End, b.Attempt, b.Err)
+}
+
+func (l *slowQueryLogger) observe(ctx context.Context, stmt string, host *gocql.HostInfo, start, end time.Time, attempt int, err error) {
+	duration := end.Sub(start)
+	if duration < l.threshold {
+		return
+	}
+	info := statementInfoFromContext(ctx)
+	e := l.logger.Warn().Err(err).
+		Str("statement", stmt).
+		Dur("duration", duration).
+		Int("attempt", attempt+1)
+	if host != nil {
+		e = e.Str("coordinator", host.ConnectAddressAndPort()).Str("datacenter", host.DataCenter())
+	}
+	if info.consistency != nil {
+		e = e.Str("consistency", info.consistency().String())
+	}
+	if info.table != "" {
+		e = e.Str("table", info.table).Str("operation", info.operation.String())
+	}
+	e.Msg("slow statement")
+}
+
+// traceLogWriter logs the Cassandra traces written by the gocql trace writer, each write is a line of the trace.
+type traceLogWriter struct {
+	logger *zerolog.Logger
+}
+
+func (w traceLogWriter) Write(p []byte) (int, error) {
+	w.logger.Info().Msg(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,106 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+	"github.com/rs/zerolog"
+)
+
+func TestSlowQueryLogger(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	l := &slowQueryLogger{threshold: 100 * time.Millisecond, logger: &logger}
+
+	info := &statementInfo{
+		table:       "ks.users",
+		operation:   opencdc.OperationUpdate,
+		consistency: func() gocql.Consistency { return gocql.LocalQuorum },
+	}
+	ctx := contextWithStatementInfo(context.Background(), info)
+	start := time.Now()
+
+	l.ObserveQuery(ctx, gocql.ObservedQuery{Statement: "UPDATE fast", Start: start, End: start.Add(10 * time.Millisecond)})
+	is.Equal(buf.Len(), 0)
+
+	l.ObserveQuery(ctx, gocql.ObservedQuery{Statement: "UPDATE slow", Start: start, End: start.Add(time.Second), Attempt: 1})
+	var entry map[string]interface{}
+	is.NoErr(json.Unmarshal(buf.Bytes(), &entry))
+	is.Equal(entry["statement"], "UPDATE slow")
+	is.Equal(entry["attempt"], float64(2))
+	is.Equal(entry["consistency"], "LOCAL_QUORUM")
+	is.Equal(entry["table"], "ks.users")
+	is.Equal(entry["operation"], "update")
+	is.Equal(entry["message"], "slow statement")
+
+	buf.Reset()
+	l.ObserveBatch(context.Background(), gocql.ObservedBatch{Statements: []string{"INSERT a", "INSERT b"}, Start: start, End: start.Add(time.Second)})
+	entry = nil
+	is.NoErr(json.Unmarshal(buf.Bytes(), &entry))
+	is.Equal(entry["statement"], "INSERT a; INSERT b")
+	_, ok := entry["consistency"]
+	is.True(!ok) // the context has no statement information
+}
+
+func TestObservers(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	m := newDestinationMetrics()
+	obs := observers{m, &slowQueryLogger{logger: &logger}}
+
+	obs.ObserveQuery(context.Background(), gocql.ObservedQuery{Statement: "INSERT", Attempt: 1})
+	obs.ObserveBatch(context.Background(), gocql.ObservedBatch{Statements: []string{"INSERT"}})
+	is.Equal(bytes.Count(buf.Bytes(), []byte("slow statement")), 2)
+}
+
+func TestDestinationConfig_ValidateTracing(t *testing.T) {
+	testCases := []struct {
+		rate    float64
+		wantErr bool
+	}{
+		{rate: 0},
+		{rate: 0.01},
+		{rate: 1},
+		{rate: -0.1, wantErr: true},
+		{rate: 1.5, wantErr: true},
+	}
+	for _, tc := range testCases {
+		is := is.New(t)
+		cfg := DestinationConfig{TracingSampleRate: tc.rate}
+		is.Equal(cfg.validateTracing() != nil, tc.wantErr)
+	}
+}
+
+func TestTraceLogWriter(t *testing.T) {
+	is := is.New(t)
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	w := traceLogWriter{logger: &logger}
+	line := []byte("Tracing session 01 (coordinator: 127.0.0.1, duration: 1ms):\n")
+	n, err := w.Write(line)
+	is.NoErr(err)
+	is.Equal(n, len(line))
+	is.Equal(buf.String(), `{"level":"info","message":"Tracing session 01 (coordinator: 127.0.0.1, duration: 1ms):"}`+"\n")
+}
@@ -0,0 +1,137 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	OTelExporterNone = "none"
+	OTelExporterOTLP = "otlp"
+)
+
+// instrumentationName is the name of the tracer creating the spans of the connector.
+const instrumentationName = "github.com/conduitio-labs/conduit-connector-cassandra"
+
+// newTracerProvider returns the tracer provider exporting the spans with the configured exporter, and a function
+// flushing and shutting it down. The provider is a no-op if no exporter is configured.
+func (d *DestinationConfig) newTracerProvider(ctx context.Context) (trace.TracerProvider, func(context.Context) error, error) {
+	if d.OTelExporter != OTelExporterOTLP {
+		return noop.NewTracerProvider(), func(context.Context) error { return nil }, nil
+	}
+	var opts []otlptracegrpc.Option
+	if d.OTelEndpoint != "" {
+		opts = append(opts, otlptracegrpc.WithEndpoint(d.OTelEndpoint))
+	}
+	if d.OTelInsecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while creating the OTLP exporter: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName("conduit-connector-cassandra"))),
+	)
+	return tp, tp.Shutdown, nil
+}
+
+// otel returns the tracer creating the spans of the destination, it's a no-op tracer if the destination wasn't opened.
+func (d *Destination) otel() trace.Tracer {
+	if d.otelTracer == nil {
+		return noop.NewTracerProvider().Tracer(instrumentationName)
+	}
+	return d.otelTracer
+}
+
+// endSpan ends the span, with an error status if err is not nil.
+func endSpan(span trace.Span, err error, opts ...trace.SpanEndOption) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(opts...)
+}
+
+// spanObserver creates a span for each attempt at executing a query or a batch, as a child of the span in the context
+// of the query.
+type spanObserver struct {
+	tracer trace.Tracer
+}
+
+func (o *spanObserver) ObserveQuery(ctx context.Context, q gocql.ObservedQuery) {
+	o.observe(ctx, "cassandra.query", q.Keyspace, q.Statement, q.Host, q.Start, q.End, q.Err,
+		attribute.Int("cassandra.rows", q.Rows),
+		attribute.Int("cassandra.attempt", q.Attempt+1),
+	)
+}
+
+func (o *spanObserver) ObserveBatch(ctx context.Context, b gocql.ObservedBatch) {
+	o.observe(ctx, "cassandra.batch", b.Keyspace, strings.Join(b.Statements, "; "), b.Host, b.Start, b.End, b.Err,
+		attribute.Int("db.operation.batch.size", len(b.Statements)),
+		attribute.Int("cassandra.attempt", b.Attempt+1),
+	)
+}
+
+func (o *spanObserver) observe(
+	ctx context.Context,
+	name, keyspace, stmt string,
+	host *gocql.HostInfo,
+	start, end time.Time,
+	err error,
+	attrs ...attribute.KeyValue,
+) {
+	info := statementInfoFromContext(ctx)
+	attrs = append(attrs, semconv.DBSystemCassandra, semconv.DBQueryText(stmt))
+	if ks, table, ok := strings.Cut(info.table, "."); ok {
+		attrs = append(attrs, semconv.DBNamespace(ks), semconv.DBCollectionName(table))
+	} else if keyspace != "" {
+		attrs = append(attrs, semconv.DBNamespace(keyspace))
+	}
+	if info.table != "" {
+		attrs = append(attrs, semconv.DBOperationName(info.operation.String()))
+	}
+	if info.consistency != nil {
+		attrs = append(attrs, semconv.DBCassandraConsistencyLevelKey.String(strings.ToLower(info.consistency().String())))
+	}
+	if host != nil {
+		attrs = append(attrs,
+			semconv.DBCassandraCoordinatorID(host.HostID()),
+			semconv.DBCassandraCoordinatorDC(host.DataCenter()),
+			semconv.ServerAddress(host.ConnectAddress().String()),
+		)
+	}
+	_, span := o.tracer.Start(ctx, name,
+		trace.WithTimestamp(start),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	endSpan(span, err, trace.WithTimestamp(end))
+}
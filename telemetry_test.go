@@ -0,0 +1,143 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+	"github.com/matryer/is"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func newTestTracer() (trace.Tracer, *tracetest.SpanRecorder) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	return tp.Tracer(instrumentationName), sr
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestSpanObserver(t *testing.T) {
+	is := is.New(t)
+	tracer, sr := newTestTracer()
+	o := &spanObserver{tracer: tracer}
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	ctx = contextWithStatementInfo(ctx, &statementInfo{
+		table:       "ks.users",
+		operation:   opencdc.OperationCreate,
+		consistency: func() gocql.Consistency { return gocql.LocalQuorum },
+	})
+	start := time.Now()
+	o.ObserveQuery(ctx, gocql.ObservedQuery{
+		Keyspace:  "ks",
+		Statement: "INSERT INTO ks.users (id) VALUES (?)",
+		Start:     start,
+		End:       start.Add(time.Millisecond),
+		Rows:      1,
+	})
+	o.ObserveBatch(ctx, gocql.ObservedBatch{
+		Statements: []string{"INSERT a", "UPDATE b"},
+		Start:      start,
+		End:        start.Add(time.Millisecond),
+		Attempt:    1,
+		Err:        errors.New("timeout"),
+	})
+	parent.End()
+
+	spans := sr.Ended()
+	is.Equal(len(spans), 3)
+
+	query := spans[0]
+	is.Equal(query.Name(), "cassandra.query")
+	is.Equal(query.Parent().SpanID(), parent.SpanContext().SpanID())
+	is.Equal(query.SpanKind(), trace.SpanKindClient)
+	is.Equal(query.StartTime(), start)
+	is.Equal(query.EndTime(), start.Add(time.Millisecond))
+	attrs := spanAttributes(query)
+	is.Equal(attrs["db.system"].AsString(), "cassandra")
+	is.Equal(attrs["db.namespace"].AsString(), "ks")
+	is.Equal(attrs["db.collection.name"].AsString(), "users")
+	is.Equal(attrs["db.operation.name"].AsString(), "create")
+	is.Equal(attrs["db.cassandra.consistency_level"].AsString(), "local_quorum")
+	is.Equal(attrs["db.query.text"].AsString(), "INSERT INTO ks.users (id) VALUES (?)")
+	is.Equal(attrs["cassandra.rows"].AsInt64(), int64(1))
+
+	batch := spans[1]
+	is.Equal(batch.Name(), "cassandra.batch")
+	is.Equal(batch.Status().Code, codes.Error)
+	attrs = spanAttributes(batch)
+	is.Equal(attrs["db.operation.batch.size"].AsInt64(), int64(2))
+	is.Equal(attrs["cassandra.attempt"].AsInt64(), int64(2))
+}
+
+func TestDestination_WriteSpans(t *testing.T) {
+	is := is.New(t)
+	tracer, sr := newTestTracer()
+	d := &Destination{otelTracer: tracer}
+
+	// the record is rejected before reaching the cluster
+	n, err := d.Write(context.Background(), []opencdc.Record{{
+		Operation: opencdc.OperationCreate,
+		Key:       opencdc.RawData("1"),
+		Payload:   opencdc.Change{After: opencdc.StructuredData{"name": "john"}},
+	}})
+	is.True(err != nil)
+	is.Equal(n, 0)
+
+	spans := sr.Ended()
+	is.Equal(len(spans), 1)
+	is.Equal(spans[0].Name(), "cassandra.Write")
+	is.Equal(spans[0].Status().Code, codes.Error)
+	attrs := spanAttributes(spans[0])
+	is.Equal(attrs["records"].AsInt64(), int64(1))
+	is.Equal(attrs["records.written"].AsInt64(), int64(0))
+}
+
+func TestDestinationConfig_NewTracerProvider(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+
+	tp, shutdown, err := (&DestinationConfig{OTelExporter: OTelExporterNone}).newTracerProvider(ctx)
+	is.NoErr(err)
+	_, ok := tp.(noop.TracerProvider)
+	is.True(ok)
+	is.NoErr(shutdown(ctx))
+
+	// the exporter connects lazily, so no collector is needed
+	tp, shutdown, err = (&DestinationConfig{OTelExporter: OTelExporterOTLP, OTelEndpoint: "127.0.0.1:4317", OTelInsecure: true}).newTracerProvider(ctx)
+	is.NoErr(err)
+	_, ok = tp.(*sdktrace.TracerProvider)
+	is.True(ok)
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_ = shutdown(shutdownCtx)
+}
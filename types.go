@@ -0,0 +1,299 @@
+// Copyright © 2023 Meroxa, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassandra
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/conduitio/conduit-commons/opencdc"
+	"github.com/gocql/gocql"
+	"github.com/hamba/avro/v2"
+	"gopkg.in/inf.v0"
+)
+
+// convertValue converts a value parsed from JSON (a string or a json.Number) into a Go type that gocql can marshal
+// into the given CQL type. Other values are returned as is.
+func convertValue(value interface{}, info gocql.TypeInfo) (interface{}, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return convertNumber(v, info)
+	case string:
+		return convertString(v, info)
+	default:
+		return value, nil
+	}
+}
+
+func convertNumber(n json.Number, info gocql.TypeInfo) (interface{}, error) {
+	switch info.Type() {
+	case gocql.TypeInt, gocql.TypeBigInt, gocql.TypeSmallInt, gocql.TypeTinyInt, gocql.TypeCounter,
+		gocql.TypeVarint, gocql.TypeTimestamp:
+		return n.Int64()
+	case gocql.TypeFloat:
+		f, err := n.Float64()
+		return float32(f), err
+	case gocql.TypeDouble:
+		return n.Float64()
+	default:
+		return n.String(), nil
+	}
+}
+
+func convertString(s string, info gocql.TypeInfo) (interface{}, error) {
+	switch info.Type() {
+	case gocql.TypeTimestamp:
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q, should be in RFC3339 format: %w", s, err)
+		}
+		return t, nil
+	case gocql.TypeDate:
+		t, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q, should be in the format YYYY-MM-DD: %w", s, err)
+		}
+		return t, nil
+	default:
+		return s, nil
+	}
+}
+
+// typeStructuredData returns a copy of data with the values of the fields converted to the Go types matching the
+// fields of the Avro record schema, so they are marshaled into the right CQL types. Nested records are converted into
+// plain maps, so they are marshaled into UDTs. Fields that are not in the schema are left as is.
+func typeStructuredData(data opencdc.StructuredData, rs *avro.RecordSchema) (opencdc.StructuredData, error) {
+	typed := copyStructuredData(data)
+	for _, f := range rs.Fields() {
+		v, ok := typed[f.Name()]
+		if !ok {
+			continue
+		}
+		tv, err := typeValue(v, f.Type())
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.Name(), err)
+		}
+		typed[f.Name()] = tv
+	}
+	return typed, nil
+}
+
+// typeValue converts the value to the Go type matching the Avro schema. Values that already have the right type, like
+// values decoded from Avro, are returned as is.
+func typeValue(value interface{}, s avro.Schema) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	s = nonNullSchema(s)
+	switch logicalType(s) {
+	case avro.Date:
+		if t, ok := value.(time.Time); ok {
+			return t, nil
+		}
+		if days, ok := toInt64(value); ok {
+			return time.Unix(days*24*60*60, 0).UTC(), nil
+		}
+		return convertString(fmt.Sprint(value), gocql.NewNativeType(0, gocql.TypeDate, ""))
+	case avro.TimestampMillis, avro.LocalTimestampMillis:
+		if ms, ok := toInt64(value); ok {
+			return time.UnixMilli(ms).UTC(), nil
+		}
+		return typeTimestamp(value)
+	case avro.TimestampMicros, avro.LocalTimestampMicros:
+		if us, ok := toInt64(value); ok {
+			return time.UnixMicro(us).UTC(), nil
+		}
+		return typeTimestamp(value)
+	case avro.TimeMillis:
+		if ms, ok := toInt64(value); ok {
+			return time.Duration(ms) * time.Millisecond, nil
+		}
+		return value, nil
+	case avro.TimeMicros:
+		if us, ok := toInt64(value); ok {
+			return time.Duration(us) * time.Microsecond, nil
+		}
+		return value, nil
+	case avro.UUID:
+		if str, ok := value.(string); ok {
+			return gocql.ParseUUID(str)
+		}
+		return value, nil
+	case avro.Decimal:
+		return typeDecimal(value, s)
+	}
+
+	switch s.Type() {
+	case avro.Int:
+		n, ok := toInt64(value)
+		if !ok || n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, fmt.Errorf("expected an int, got %v", value)
+		}
+		return int32(n), nil
+	case avro.Long:
+		n, ok := toInt64(value)
+		if !ok {
+			return nil, fmt.Errorf("expected a long, got %v", value)
+		}
+		return n, nil
+	case avro.Float:
+		f, ok := toFloat64(value)
+		if !ok {
+			return nil, fmt.Errorf("expected a float, got %v", value)
+		}
+		return float32(f), nil
+	case avro.Double:
+		f, ok := toFloat64(value)
+		if !ok {
+			return nil, fmt.Errorf("expected a double, got %v", value)
+		}
+		return f, nil
+	case avro.Bytes, avro.Fixed:
+		// bytes are encoded in base64 in JSON
+		if str, ok := value.(string); ok {
+			return base64.StdEncoding.DecodeString(str)
+		}
+		return value, nil
+	case avro.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return value, nil
+		}
+		typed := make([]interface{}, len(items))
+		for i, item := range items {
+			tv, err := typeValue(item, s.(*avro.ArraySchema).Items())
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i, err)
+			}
+			typed[i] = tv
+		}
+		return typed, nil
+	case avro.Map:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		typed := make(map[string]interface{}, len(values))
+		for k, v := range values {
+			tv, err := typeValue(v, s.(*avro.MapSchema).Values())
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %w", k, err)
+			}
+			typed[k] = tv
+		}
+		return typed, nil
+	case avro.Record:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return value, nil
+		}
+		typed, err := typeStructuredData(fields, s.(*avro.RecordSchema))
+		if err != nil {
+			return nil, err
+		}
+		// gocql only marshals plain maps into UDTs, not named map types like opencdc.StructuredData
+		return map[string]interface{}(typed), nil
+	default:
+		return value, nil
+	}
+}
+
+func typeTimestamp(value interface{}) (interface{}, error) {
+	if str, ok := value.(string); ok {
+		return convertString(str, gocql.NewNativeType(0, gocql.TypeTimestamp, ""))
+	}
+	return value, nil
+}
+
+// typeDecimal converts a decimal, decoded from Avro as a *big.Rat or from JSON as a number or a string, into an
+// *inf.Dec with the scale of the schema.
+func typeDecimal(value interface{}, s avro.Schema) (interface{}, error) {
+	scale := 0
+	if ds, ok := s.(avro.LogicalTypeSchema).Logical().(*avro.DecimalLogicalSchema); ok {
+		scale = ds.Scale()
+	}
+	var str string
+	switch v := value.(type) {
+	case *big.Rat:
+		str = v.FloatString(scale)
+	case json.Number:
+		str = v.String()
+	case string:
+		str = v
+	case float64:
+		str = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return value, nil
+	}
+	dec, ok := new(inf.Dec).SetString(str)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal %q", str)
+	}
+	return dec, nil
+}
+
+// toInt64 returns the value as an int64 if it's an integer number.
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case float32:
+		return int64(v), float32(int64(v)) == v
+	case float64:
+		return int64(v), float64(int64(v)) == v
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// toFloat64 returns the value as a float64 if it's a number.
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		if n, ok := toInt64(value); ok {
+			return float64(n), true
+		}
+		return 0, false
+	}
+}